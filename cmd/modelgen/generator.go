@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// initialisms are the words written in upper case in Go names.
+var initialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "UUID": true, "IP": true, "API": true, "HTTP": true,
+	"JSON": true, "SQL": true, "XML": true, "HTML": true, "CSS": true,
+}
+
+// goTypes records the Go type of every MySQL column type, the integer types are handled by goType. Decimals are
+// strings so that their exact value is kept.
+var goTypes = map[string]string{
+	"decimal": "string", "numeric": "string", "float": "float64", "double": "float64", "real": "float64",
+	"char": "string", "varchar": "string", "tinytext": "string", "text": "string", "mediumtext": "string",
+	"longtext": "string", "enum": "string", "set": "string", "json": "string", "time": "string",
+	"date": "time.Time", "datetime": "time.Time", "timestamp": "time.Time", "year": "int",
+	"binary": "[]byte", "varbinary": "[]byte", "tinyblob": "[]byte", "blob": "[]byte", "mediumblob": "[]byte",
+	"longblob": "[]byte", "bit": "[]byte",
+}
+
+type (
+	// model records the Go names of a table
+	model struct {
+		Table      string
+		Struct     string
+		Var        string
+		Private    string
+		ColumnType string
+		Columns    []field
+	}
+
+	// field records the Go names of a column
+	field struct {
+		Column        string
+		Name          string
+		Const         string
+		Type          string
+		AutoIncrement bool
+	}
+)
+
+// modelTemplate renders the models of the tables.
+var modelTemplate = template.Must(template.New("models").Parse(`// Code generated by modelgen from {{.Source}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"strings"
+{{- if .UsesTime}}
+	"time"
+{{- end}}
+
+	"github.com/atrapalo/go-base/mysql"
+)
+{{range .Models}}{{$model := .}}
+// {{.Struct}} records a row of the {{.Table}} table
+type {{.Struct}} struct {
+{{- range .Columns}}
+	{{.Name}} {{.Type}} ` + "`db:\"{{.Column}}\"`" + `
+{{- end}}
+}
+
+// {{.ColumnType}} is the name of a column of the {{.Table}} table
+type {{.ColumnType}} string
+
+// The columns of the {{.Table}} table.
+const (
+{{- range .Columns}}
+	{{.Const}} {{$model.ColumnType}} = "{{.Column}}"
+{{- end}}
+)
+
+// {{.Var}} is the {{.Table}} table
+var {{.Var}} = {{.Private}}Table{
+	Name: "{{.Table}}",
+	Cols: {{.Private}}Columns{
+{{- range .Columns}}
+		{{.Name}}: {{.Const}},
+{{- end}}
+	},
+}
+
+type (
+	// {{.Private}}Table records the name and the columns of the {{.Table}} table
+	{{.Private}}Table struct {
+		Name string
+		Cols {{.Private}}Columns
+	}
+
+	// {{.Private}}Columns records the columns of the {{.Table}} table
+	{{.Private}}Columns struct {
+{{- range .Columns}}
+		{{.Name}} {{$model.ColumnType}}
+{{- end}}
+	}
+)
+
+// Columns returns the columns of the {{.Table}} table in definition order.
+func ({{.Private}}Table) Columns() []{{.ColumnType}} {
+	return []{{.ColumnType}}{ {{- range $i, $c := .Columns}}{{if $i}}, {{end}}{{$c.Const}}{{end -}} }
+}
+
+// Select returns QueryBuilder of the connection that selects the given columns, or every column when none is given,
+// from the {{.Table}} table.
+func (table {{.Private}}Table) Select(connection *mysql.Connection, columns ...{{.ColumnType}}) *mysql.QueryBuilder {
+	if len(columns) == 0 {
+		columns = table.Columns()
+	}
+
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, mysql.QuoteIdentifier(string(column)))
+	}
+
+	return connection.NewQueryBuilder().Select(strings.Join(quoted, ", ")).From(table.Name, "")
+}
+
+// Insert returns QueryBuilder of the connection that inserts the row into the {{.Table}} table, the AUTO_INCREMENT
+// columns are left to the database.
+func (table {{.Private}}Table) Insert(connection *mysql.Connection, row {{.Struct}}) *mysql.QueryBuilder {
+	return connection.NewQueryBuilder().Insert(table.Name){{range .Columns}}{{if not .AutoIncrement}}.
+		Value(string({{.Const}}), row.{{.Name}}){{end}}{{end}}
+}
+
+// Update returns QueryBuilder of the connection that updates the rows of the {{.Table}} table.
+func (table {{.Private}}Table) Update(connection *mysql.Connection) *mysql.QueryBuilder {
+	return connection.NewQueryBuilder().Update(table.Name, "")
+}
+
+// Delete returns QueryBuilder of the connection that deletes the rows of the {{.Table}} table, or soft deletes them
+// when the connection has a soft delete scope for it.
+func (table {{.Private}}Table) Delete(connection *mysql.Connection) *mysql.QueryBuilder {
+	return connection.NewQueryBuilder().Delete(table.Name)
+}
+{{end}}`))
+
+// Generate returns the formatted Go source of the models of the tables, in the given package.
+func Generate(packageName string, source string, tables []Table) ([]byte, error) {
+	models := make([]model, 0, len(tables))
+	usesTime := false
+	names := map[string]string{}
+
+	for _, table := range tables {
+		m := newModel(table)
+		if other, ok := names[m.Struct]; ok {
+			return nil, fmt.Errorf("tables %s and %s are both generated as %s", other, table.Name, m.Struct)
+		}
+		names[m.Struct] = table.Name
+
+		for _, f := range m.Columns {
+			usesTime = usesTime || strings.Contains(f.Type, "time.Time")
+		}
+		models = append(models, m)
+	}
+
+	var buffer bytes.Buffer
+	err := modelTemplate.Execute(&buffer, map[string]interface{}{
+		"Package":  packageName,
+		"Source":   source,
+		"UsesTime": usesTime,
+		"Models":   models,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buffer.Bytes())
+}
+
+// newModel returns the Go names of the table.
+func newModel(table Table) model {
+	m := model{Table: table.Name, Struct: exported(singular(table.Name)), Var: exported(table.Name)}
+	if m.Var == m.Struct {
+		m.Var += "Table"
+	}
+	m.Private = unexported(m.Var)
+	m.ColumnType = m.Struct + "Column"
+
+	for _, column := range table.Columns {
+		name := exported(column.Name)
+		m.Columns = append(m.Columns, field{
+			Column:        column.Name,
+			Name:          name,
+			Const:         m.ColumnType + name,
+			Type:          goType(column),
+			AutoIncrement: column.AutoIncrement,
+		})
+	}
+
+	return m
+}
+
+// goType returns the Go type of the column, a pointer when the column is nullable.
+func goType(column Column) string {
+	var t string
+
+	switch column.Type {
+	case "tinyint", "smallint", "mediumint", "int", "integer":
+		t = "int"
+		if column.Type == "tinyint" && column.Args == "1" {
+			t = "bool"
+		}
+	case "bigint":
+		t = "int64"
+	case "bool", "boolean":
+		t = "bool"
+	default:
+		var ok bool
+		if t, ok = goTypes[column.Type]; !ok {
+			t = "[]byte"
+		}
+	}
+
+	if column.Unsigned && strings.HasPrefix(t, "int") {
+		t = "u" + t
+	}
+
+	if column.Nullable && t != "[]byte" {
+		t = "*" + t
+	}
+
+	return t
+}
+
+// exported returns the snake case name in camel case, starting with an upper case letter.
+func exported(name string) string {
+	var builder strings.Builder
+
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			builder.WriteString(upper)
+			continue
+		}
+		builder.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	result := builder.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "X" + result
+	}
+
+	return result
+}
+
+// unexported returns the camel case name starting with a lower case letter.
+func unexported(name string) string {
+	runes := []rune(name)
+
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	if i > 1 && i < len(runes) {
+		i--
+	}
+	for j := 0; j < i; j++ {
+		runes[j] = unicode.ToLower(runes[j])
+	}
+
+	return string(runes)
+}
+
+// singular returns the English singular of the plural table name, only its last word is changed.
+func singular(name string) string {
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return name
+	case strings.HasSuffix(lower, "s") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+
+	return name
+}
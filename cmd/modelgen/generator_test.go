@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_generate(t *testing.T) {
+	source, err := Generate("models", "schema.sql", []Table{{Name: "hotel_categories", Columns: []Column{
+		{Name: "id", Type: "int", Unsigned: true, AutoIncrement: true},
+		{Name: "hotel_url", Type: "varchar", Args: "255", Nullable: true},
+		{Name: "created_at", Type: "datetime"},
+		{Name: "price", Type: "decimal", Args: "10,2"},
+	}}})
+
+	assert.Nil(t, err)
+
+	code := string(source)
+	assert.Contains(t, code, "// Code generated by modelgen from schema.sql; DO NOT EDIT.\n\npackage models\n")
+	assert.Contains(t, code, "\t\"time\"\n")
+	assert.Contains(t, code, "type HotelCategory struct {\n"+
+		"\tID        uint      `db:\"id\"`\n"+
+		"\tHotelURL  *string   `db:\"hotel_url\"`\n"+
+		"\tCreatedAt time.Time `db:\"created_at\"`\n"+
+		"\tPrice     string    `db:\"price\"`\n}")
+	assert.Contains(t, code, "\tHotelCategoryColumnHotelURL  HotelCategoryColumn = \"hotel_url\"\n")
+	assert.Contains(t, code, "var HotelCategories = hotelCategoriesTable{\n\tName: \"hotel_categories\",\n")
+	assert.Contains(t, code, "func (table hotelCategoriesTable) Select(connection *mysql.Connection, columns ...HotelCategoryColumn) *mysql.QueryBuilder {")
+	assert.Contains(t, code, "Insert(table.Name).\n"+
+		"\t\tValue(string(HotelCategoryColumnHotelURL), row.HotelURL).\n"+
+		"\t\tValue(string(HotelCategoryColumnCreatedAt), row.CreatedAt).\n"+
+		"\t\tValue(string(HotelCategoryColumnPrice), row.Price)\n}")
+	assert.Contains(t, code, "return connection.NewQueryBuilder().Delete(table.Name)")
+}
+
+func Test_generate_name_collision(t *testing.T) {
+	_, err := Generate("models", "schema.sql", []Table{{Name: "hotels"}, {Name: "hotel"}})
+
+	assert.EqualError(t, err, "tables hotels and hotel are both generated as Hotel")
+}
+
+func Test_go_type(t *testing.T) {
+	cases := map[string]Column{
+		"bool":      {Type: "tinyint", Args: "1"},
+		"*int":      {Type: "smallint", Nullable: true},
+		"uint64":    {Type: "bigint", Unsigned: true},
+		"float64":   {Type: "double"},
+		"*string":   {Type: "decimal", Args: "10,2", Nullable: true},
+		"string":    {Type: "enum", Args: "'a','b'"},
+		"time.Time": {Type: "timestamp"},
+		"[]byte":    {Type: "blob", Nullable: true},
+	}
+
+	for expected, column := range cases {
+		assert.Equal(t, expected, goType(column))
+	}
+}
+
+func Test_names(t *testing.T) {
+	assert.Equal(t, "HotelURL", exported("hotel_url"))
+	assert.Equal(t, "X2fa", exported("2fa"))
+	assert.Equal(t, "urlHotels", unexported("URLHotels"))
+	assert.Equal(t, "category", singular("categories"))
+	assert.Equal(t, "address", singular("addresses"))
+	assert.Equal(t, "status", singular("status"))
+}
@@ -0,0 +1,93 @@
+// Command modelgen reads the CREATE TABLE statements of SQL files and generates, for every table, a Go struct with
+// db tags, typed column name constants and QueryBuilder helpers of the mysql package.
+//
+// Usage:
+//
+//	modelgen [-package models] [-out models_gen.go] schema.sql migrations/
+//
+// Directories are read for their .sql files, in name order. No database connection is needed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	packageName := flag.String("package", "models", "package of the generated file")
+	out := flag.String("out", "", "file to write, the standard output when empty")
+	flag.Parse()
+
+	if err := run(*packageName, *out, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "modelgen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the models of the tables created by the SQL files at the given paths and writes them to out.
+func run(packageName string, out string, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no SQL file given")
+	}
+
+	files, err := sqlFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	tables := make([]Table, 0)
+	for _, file := range files {
+		script, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		fileTables, err := ParseTables(string(script))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		tables = append(tables, fileTables...)
+	}
+
+	source, err := Generate(packageName, strings.Join(paths, ", "), tables)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+
+	return os.WriteFile(out, source, 0644)
+}
+
+// sqlFiles returns the given files and the .sql files of the given directories.
+func sqlFiles(paths []string) ([]string, error) {
+	files := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, "*.sql"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// createTablePattern matches the start of a CREATE TABLE statement up to its definitions.
+var createTablePattern = regexp.MustCompile("(?i)CREATE\\s+(?:TEMPORARY\\s+)?TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?((?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?)\\s*\\(")
+
+// indexKeywords are the first words of the table definitions that are not columns.
+var indexKeywords = map[string]bool{
+	"PRIMARY": true, "KEY": true, "INDEX": true, "UNIQUE": true, "CONSTRAINT": true,
+	"FOREIGN": true, "FULLTEXT": true, "SPATIAL": true, "CHECK": true,
+}
+
+type (
+	// Table records the name and the columns of a table
+	Table struct {
+		Name    string
+		Columns []Column
+	}
+
+	// Column records the name, the lower cased type without arguments, the type arguments and the flags of a column
+	Column struct {
+		Name          string
+		Type          string
+		Args          string
+		Unsigned      bool
+		Nullable      bool
+		AutoIncrement bool
+	}
+)
+
+// ParseTables returns the tables of every CREATE TABLE statement of the SQL script, other statements are ignored.
+func ParseTables(script string) ([]Table, error) {
+	script = stripComments(script)
+	tables := make([]Table, 0)
+
+	for _, match := range createTablePattern.FindAllStringSubmatchIndex(maskStrings(script), -1) {
+		name := script[match[2]:match[3]]
+		if dot := strings.LastIndex(name, "."); dot != -1 {
+			name = name[dot+1:]
+		}
+		name = strings.Trim(name, "`")
+
+		end := closingParen(script, match[1]-1)
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated CREATE TABLE statement of table %s", name)
+		}
+
+		table := Table{Name: name}
+		primaryKey := map[string]bool{}
+		for _, definition := range splitDefinitions(script[match[1]:end]) {
+			for _, column := range primaryKeyColumns(definition) {
+				primaryKey[column] = true
+			}
+
+			column, ok, err := parseColumn(definition)
+			if err != nil {
+				return nil, fmt.Errorf("table %s: %w", name, err)
+			}
+			if ok {
+				table.Columns = append(table.Columns, column)
+			}
+		}
+
+		for i, column := range table.Columns {
+			if primaryKey[column.Name] {
+				table.Columns[i].Nullable = false
+			}
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// parseColumn returns the column of a table definition, or false when the definition is an index or a constraint.
+func parseColumn(definition string) (Column, bool, error) {
+	name, rest := nextWord(definition)
+	if name == "" {
+		return Column{}, false, nil
+	}
+	if indexKeywords[strings.ToUpper(name)] {
+		return Column{}, false, nil
+	}
+
+	columnType, rest := nextWord(rest)
+	if columnType == "" {
+		return Column{}, false, fmt.Errorf("column %s has no type", name)
+	}
+
+	column := Column{Name: strings.Trim(name, "`"), Type: strings.ToLower(columnType), Nullable: true}
+
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		end := closingParen(rest, 0)
+		if end == -1 {
+			return Column{}, false, fmt.Errorf("column %s has unterminated type arguments", column.Name)
+		}
+		column.Args = strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	flags := " " + strings.ToUpper(collapseSpaces(stripQuoted(rest))) + " "
+	column.Unsigned = strings.Contains(flags, " UNSIGNED ")
+	column.Nullable = !strings.Contains(flags, " NOT NULL ") && !strings.Contains(flags, " PRIMARY KEY ")
+	column.AutoIncrement = strings.Contains(flags, " AUTO_INCREMENT ")
+
+	return column, true, nil
+}
+
+// primaryKeyColumns returns the columns of a PRIMARY KEY table definition, or none for other definitions.
+func primaryKeyColumns(definition string) []string {
+	upper := strings.ToUpper(collapseSpaces(stripQuoted(definition)))
+	if !strings.HasPrefix(upper, "PRIMARY KEY") && !(strings.HasPrefix(upper, "CONSTRAINT") && strings.Contains(upper, " PRIMARY KEY")) {
+		return nil
+	}
+
+	open := strings.Index(definition, "(")
+	if open == -1 {
+		return nil
+	}
+	end := closingParen(definition, open)
+	if end == -1 {
+		return nil
+	}
+
+	columns := make([]string, 0)
+	for _, part := range splitDefinitions(definition[open+1 : end]) {
+		column, _ := nextWord(part)
+		columns = append(columns, strings.Trim(column, "`"))
+	}
+
+	return columns
+}
+
+// nextWord returns the first word of the value, a backtick quoted identifier or a run of characters other than
+// spaces and parentheses, and the rest of the value.
+func nextWord(value string) (string, string) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "`") {
+		end := strings.Index(value[1:], "`")
+		if end == -1 {
+			return value, ""
+		}
+		return value[:end+2], value[end+2:]
+	}
+
+	end := strings.IndexAny(value, " \t\r\n(")
+	if end == -1 {
+		return value, ""
+	}
+
+	return value[:end], value[end:]
+}
+
+// splitDefinitions returns the comma separated table definitions, ignoring the commas inside parentheses and quotes.
+func splitDefinitions(body string) []string {
+	definitions := make([]string, 0)
+	depth, start := 0, 0
+
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\'', '"', '`':
+			i = skipQuote(body, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				definitions = append(definitions, strings.TrimSpace(body[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(definitions, strings.TrimSpace(body[start:]))
+}
+
+// closingParen returns the position of the parenthesis closing the one at position open, or -1 when there is none.
+func closingParen(value string, open int) int {
+	depth := 0
+
+	for i := open; i < len(value); i++ {
+		switch value[i] {
+		case '\'', '"', '`':
+			i = skipQuote(value, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// skipQuote returns the position of the quote closing the one at position i.
+func skipQuote(value string, i int) int {
+	quote := value[i]
+
+	for j := i + 1; j < len(value); j++ {
+		switch value[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			if j+1 < len(value) && value[j+1] == quote {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+
+	return len(value)
+}
+
+// stripComments returns the SQL script without its comments, keeping the quoted values untouched.
+func stripComments(script string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := skipQuote(script, i)
+			if end >= len(script) {
+				end = len(script) - 1
+			}
+			builder.WriteString(script[i : end+1])
+			i = end
+		case c == '-' && strings.HasPrefix(script[i:], "--"), c == '#':
+			end := strings.IndexByte(script[i:], '\n')
+			if end == -1 {
+				return builder.String()
+			}
+			i += end - 1
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end == -1 {
+				return builder.String()
+			}
+			builder.WriteByte(' ')
+			i += end + 3
+		default:
+			builder.WriteByte(c)
+		}
+	}
+
+	return builder.String()
+}
+
+// stripQuoted returns the value without its quoted values, so that defaults and comments are not taken as flags.
+func stripQuoted(value string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c == '\'' || c == '"' || c == '`' {
+			i = skipQuote(value, i)
+			builder.WriteByte(' ')
+			continue
+		}
+		builder.WriteByte(value[i])
+	}
+
+	return builder.String()
+}
+
+// maskStrings returns the SQL script with the content of its string literals replaced by spaces, keeping every
+// position, so that statements quoted in values are not matched.
+func maskStrings(script string) string {
+	masked := []byte(script)
+
+	for i := 0; i < len(masked); i++ {
+		switch masked[i] {
+		case '`':
+			i = skipQuote(script, i)
+		case '\'', '"':
+			end := skipQuote(script, i)
+			for j := i + 1; j < end && j < len(masked); j++ {
+				masked[j] = ' '
+			}
+			i = end
+		}
+	}
+
+	return string(masked)
+}
+
+// collapseSpaces returns the value with every run of white space replaced by a single space.
+func collapseSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
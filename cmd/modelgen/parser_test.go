@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_parse_tables(t *testing.T) {
+	tables, err := ParseTables(`
+-- the hotels, a comment with CREATE TABLE fake (id int)
+CREATE TABLE IF NOT EXISTS ` + "`shop`.`hotels`" + ` (
+  ` + "`id`" + ` bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(255) NOT NULL DEFAULT 'a, NULL',
+  price decimal(10,2) COMMENT 'not null',
+  /* inline */ active tinyint(1) NOT NULL,
+  PRIMARY KEY (` + "`id`" + `),
+  KEY idx_name (name, price)
+) ENGINE=InnoDB;
+INSERT INTO hotels (name) VALUES ('CREATE TABLE x (y int)');
+CREATE TABLE categories (code char(3), CONSTRAINT pk PRIMARY KEY (code));`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Table{
+		{Name: "hotels", Columns: []Column{
+			{Name: "id", Type: "bigint", Unsigned: true, AutoIncrement: true},
+			{Name: "name", Type: "varchar", Args: "255"},
+			{Name: "price", Type: "decimal", Args: "10,2", Nullable: true},
+			{Name: "active", Type: "tinyint", Args: "1"},
+		}},
+		{Name: "categories", Columns: []Column{
+			{Name: "code", Type: "char", Args: "3"},
+		}},
+	}, tables)
+}
+
+func Test_parse_tables_unterminated(t *testing.T) {
+	_, err := ParseTables("CREATE TABLE hotels (id int, name varchar(10)")
+
+	assert.EqualError(t, err, "unterminated CREATE TABLE statement of table hotels")
+}
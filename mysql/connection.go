@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -12,9 +13,15 @@ const driver = "mysql"
 const connMaxLifeTimeSeconds = 0 //0 means infinite
 const maxIdleConnections = 50
 const maxOpenConnections = 50
+const statementCacheSize = 100
 
 type Connection struct {
-	db *sql.DB
+	db                *sql.DB
+	statements        *statementCache
+	scopes            []Scope
+	debugValueLength  int
+	planRowsThreshold int64
+	planWarningHook   PlanWarningHook
 }
 
 func NewConnection(dbHost string, dbPort string, dbUser string, dbPass string, dbName string) *Connection {
@@ -27,28 +34,64 @@ func NewConnection(dbHost string, dbPort string, dbUser string, dbPass string, d
 	db.SetMaxIdleConns(maxIdleConnections)
 	db.SetMaxOpenConns(maxOpenConnections)
 
+	return NewConnectionFromDB(db)
+}
+
+func NewConnectionFromDB(db *sql.DB) *Connection {
 	return &Connection{
-		db: db,
+		db:               db,
+		statements:       newStatementCache(db, statementCacheSize),
+		debugValueLength: DefaultDebugValueLength,
 	}
 }
 
 func (c *Connection) NewQueryBuilder() *QueryBuilder {
-	return NewQueryBuilder(c.db)
+	queryBuilder := c.newQueryBuilder(c.db)
+	queryBuilder.statements = c.statements
+
+	return queryBuilder
+}
+
+func (c *Connection) NewQueryBuilderTx(tx *sql.Tx) *QueryBuilder {
+	return c.newQueryBuilder(tx)
+}
+
+func (c *Connection) NewQueryBuilderConn(conn *sql.Conn) *QueryBuilder {
+	return c.newQueryBuilder(conn)
+}
+
+// newQueryBuilder returns a QueryBuilder of the database with the scopes, the debug value length and the plan
+// warning hook of the connection.
+func (c *Connection) newQueryBuilder(database Executor) *QueryBuilder {
+	queryBuilder := NewQueryBuilder(database)
+	queryBuilder.scopes = c.scopes
+	queryBuilder.debugValueLength = c.debugValueLength
+	queryBuilder.SetPlanWarningHook(c.planRowsThreshold, c.planWarningHook)
+
+	return queryBuilder
 }
 
 func (c *Connection) Execute(query string, params ...interface{}) (sql.Result, error) {
-	result, err := c.db.Exec(query, params...)
+	return c.ExecuteContext(context.Background(), query, params...)
+}
+
+func (c *Connection) ExecuteContext(ctx context.Context, query string, params ...interface{}) (sql.Result, error) {
+	result, err := c.db.ExecContext(ctx, query, params...)
 	if err != nil {
-		err = errors.New(fmt.Sprintf("Error %s when running SQL Execute method - query: %s", err, query))
+		err = fmt.Errorf("Error %w when running SQL Execute method - query: %s", err, c.debugSQL(query, params))
 	}
 
 	return result, err
 }
 
 func (c *Connection) Query(query string, params ...interface{}) (*sql.Rows, error) {
-	rows, err := c.db.Query(query, params...)
+	return c.QueryContext(context.Background(), query, params...)
+}
+
+func (c *Connection) QueryContext(ctx context.Context, query string, params ...interface{}) (*sql.Rows, error) {
+	rows, err := c.db.QueryContext(ctx, query, params...)
 	if err != nil {
-		err = errors.New(fmt.Sprintf("Error %s when running SQL Query method - query: %s", err, query))
+		err = fmt.Errorf("Error %w when running SQL Query method - query: %s", err, c.debugSQL(query, params))
 	}
 
 	return rows, err
@@ -84,7 +127,7 @@ func (c *Connection) RollbackTransaction(transaction *sql.Tx) error {
 func (c *Connection) ExecuteWithTransaction(tx *sql.Tx, query string, params ...interface{}) (sql.Result, error) {
 	result, err := tx.Exec(query, params...)
 	if err != nil {
-		err = errors.New(fmt.Sprintf("Error %s when running SQL ExecuteWithTransaction method - query: %s", err, query))
+		err = fmt.Errorf("Error %w when running SQL ExecuteWithTransaction method - query: %s", err, c.debugSQL(query, params))
 	}
 
 	return result, err
@@ -93,12 +136,45 @@ func (c *Connection) ExecuteWithTransaction(tx *sql.Tx, query string, params ...
 func (c *Connection) QueryWithTransaction(tx *sql.Tx, query string, params ...interface{}) (*sql.Rows, error) {
 	result, err := tx.Query(query, params...)
 	if err != nil {
-		err = errors.New(fmt.Sprintf("Error %s when running SQL QueryWithTransaction method - query: %s", err, query))
+		err = fmt.Errorf("Error %w when running SQL QueryWithTransaction method - query: %s", err, c.debugSQL(query, params))
 	}
 
 	return result, err
 }
 
+func (c *Connection) SetDebugValueLength(length int) {
+	c.debugValueLength = length
+}
+
+func (c *Connection) SetPlanWarningHook(rowsThreshold int64, hook PlanWarningHook) {
+	c.planRowsThreshold = rowsThreshold
+	c.planWarningHook = hook
+}
+
+func (c *Connection) debugSQL(query string, params []interface{}) string {
+	return InterpolateSQL(query, params, c.debugValueLength)
+}
+
+func (c *Connection) SetStatementCacheSize(size int) {
+	c.statements.resize(size)
+}
+
+func (c *Connection) StatementCacheStats() StatementCacheStats {
+	return c.statements.stats()
+}
+
+func (c *Connection) Close() error {
+	err := c.statements.close()
+	if dbErr := c.db.Close(); err == nil {
+		err = dbErr
+	}
+	if err != nil {
+		err = fmt.Errorf("Error %w when running SQL Close method", err)
+	}
+
+	return err
+}
+
 func buildDsn(dbHost string, dbPort string, dbUser string, dbPass string, dbName string) string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", dbUser, dbPass, dbHost, dbPort, dbName)
 }
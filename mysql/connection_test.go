@@ -0,0 +1,76 @@
+package mysql_test
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
+	"testing"
+)
+
+func Test_connection_caches_prepared_statements(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	updateSql := regexp.QuoteMeta("UPDATE `hotel` SET `stars` = ? WHERE id = ?")
+	deleteSql := regexp.QuoteMeta("DELETE FROM `hotel` WHERE id = ?")
+	insertSql := regexp.QuoteMeta("INSERT INTO `hotel` (`name`) VALUES (?)")
+
+	mock.ExpectPrepare(updateSql).WillBeClosed()
+	mock.ExpectExec(updateSql).WithArgs(4, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(updateSql).WithArgs(5, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(deleteSql).WillBeClosed()
+	mock.ExpectExec(deleteSql).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(insertSql).WillBeClosed()
+	mock.ExpectExec(insertSql).WithArgs("Arts").WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectClose()
+
+	connection := mysql.NewConnectionFromDB(db)
+	connection.SetStatementCacheSize(2)
+
+	for i, stars := range []int{4, 5} {
+		affected, err := connection.NewQueryBuilder().Update("hotel", "").Set("stars", stars).Where("id = ?", i+1).PrepareAndExecute()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), affected)
+	}
+
+	_, err2 := connection.NewQueryBuilder().Delete("hotel").Where("id = ?", 3).PrepareAndExecute()
+	assert.Nil(t, err2)
+
+	id, err3 := connection.NewQueryBuilder().Insert("hotel").Value("name", "Arts").PrepareAndExecute()
+	assert.Nil(t, err3)
+	assert.Equal(t, int64(7), id)
+
+	assert.Equal(t, mysql.StatementCacheStats{Hits: 1, Misses: 3, Evictions: 1, Size: 2}, connection.StatementCacheStats())
+
+	assert.Nil(t, connection.Close())
+	assert.Equal(t, 0, connection.StatementCacheStats().Size)
+
+	_, err4 := connection.NewQueryBuilder().Delete("hotel").Where("id = ?", 3).PrepareAndExecute()
+	assert.True(t, errors.Is(err4, mysql.ErrStatementCacheClosed))
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
+
+func Test_connection_errors_include_interpolated_query(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectExec("UPDATE hotel").WithArgs("Arts", 7).WillReturnError(errors.New("lock wait timeout"))
+
+	_, err2 := mysql.NewConnectionFromDB(db).Execute("UPDATE hotel SET name = ? WHERE id = ?", "Arts", 7)
+	assert.EqualError(t, err2, "Error lock wait timeout when running SQL Execute method - query: UPDATE hotel SET name = 'Arts' WHERE id = 7")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT rooms").WithArgs(7).WillReturnError(sql.ErrConnDone)
+
+	tx, err3 := db.Begin()
+	assert.Nil(t, err3)
+
+	_, err4 := mysql.NewConnectionFromDB(db).QueryWithTransaction(tx, "SELECT rooms FROM hotel WHERE id = ?", 7)
+	assert.True(t, errors.Is(err4, sql.ErrConnDone))
+	assert.EqualError(t, err4, "Error sql: connection is already closed when running SQL QueryWithTransaction method - query: SELECT rooms FROM hotel WHERE id = 7")
+}
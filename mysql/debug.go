@@ -0,0 +1,136 @@
+package mysql
+
+import (
+	sqldriver "database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultDebugValueLength is the default max length of the values interpolated in debug SQL, longer ones are
+// truncated.
+const DefaultDebugValueLength = 256
+
+// debugDateTimeFormat is the format of the times interpolated in debug SQL.
+const debugDateTimeFormat = "2006-01-02 15:04:05.999999"
+
+// mysqlEscapes records the escape sequence of every character escaped in MySQL string literals.
+var mysqlEscapes = strings.NewReplacer(
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\\", `\\`,
+	"'", `\'`,
+	"\"", `\"`,
+	"\x1a", `\Z`,
+)
+
+// DebugSQL returns the SQL string with every param interpolated as a MySQL literal, for logs and debugging only.
+// Values longer than the debug value length are truncated.
+func (queryBuilder *QueryBuilder) DebugSQL() string {
+	sqlString, params, _ := queryBuilder.buildPositional()
+
+	return InterpolateSQL(sqlString, params, queryBuilder.debugValueLength)
+}
+
+// SetDebugValueLength returns QueryBuilder that truncates the values interpolated by DebugSQL beyond the given
+// length, zero or less keeps them whole.
+func (queryBuilder *QueryBuilder) SetDebugValueLength(length int) *QueryBuilder {
+	queryBuilder.debugValueLength = length
+
+	return queryBuilder
+}
+
+// InterpolateSQL returns the SQL string with every ? placeholder replaced by its param as a MySQL literal,
+// truncating the values beyond maxValueLength unless it is zero or less. It is meant for logs, never run its result.
+func InterpolateSQL(sqlString string, params []interface{}, maxValueLength int) string {
+	if len(params) == 0 {
+		return sqlString
+	}
+
+	var builder strings.Builder
+	next := 0
+
+	_ = walkPlaceholders(sqlString, func(chunk string, placeholder string) error {
+		builder.WriteString(chunk)
+
+		if placeholder != "?" || next >= len(params) {
+			builder.WriteString(placeholder)
+			return nil
+		}
+
+		builder.WriteString(debugLiteral(params[next], maxValueLength))
+		next++
+
+		return nil
+	})
+
+	return builder.String()
+}
+
+// debugLiteral returns the value as a MySQL literal, pointers are dereferenced and nil ones are NULL.
+func debugLiteral(value interface{}, maxValueLength int) string {
+	for pointer := reflect.ValueOf(value); pointer.Kind() == reflect.Ptr; pointer = reflect.ValueOf(value) {
+		if pointer.IsNil() {
+			return "NULL"
+		}
+		if _, ok := value.(sqldriver.Valuer); ok {
+			break
+		}
+		value = pointer.Elem().Interface()
+	}
+
+	if valuer, ok := value.(sqldriver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "?"
+		}
+		value = v
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return "'" + v.Format(debugDateTimeFormat) + "'"
+	case []byte:
+		if !utf8.Valid(v) {
+			return "X'" + truncateDebugValue(hex.EncodeToString(v), maxValueLength) + "'"
+		}
+		return debugString(string(v), maxValueLength)
+	case string:
+		return debugString(v, maxValueLength)
+	default:
+		return debugString(fmt.Sprint(v), maxValueLength)
+	}
+}
+
+// debugString returns the value as an escaped MySQL string literal.
+func debugString(value string, maxValueLength int) string {
+	return "'" + mysqlEscapes.Replace(truncateDebugValue(value, maxValueLength)) + "'"
+}
+
+// truncateDebugValue returns the first maxValueLength characters of the value followed by an ellipsis when it is
+// longer.
+func truncateDebugValue(value string, maxValueLength int) string {
+	if maxValueLength <= 0 || utf8.RuneCountInString(value) <= maxValueLength {
+		return value
+	}
+
+	return string([]rune(value)[:maxValueLength]) + "..."
+}
@@ -0,0 +1,41 @@
+package mysql_test
+
+import (
+	"database/sql"
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func Test_debug_sql(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	created := time.Date(2022, 10, 1, 10, 30, 0, 0, time.UTC)
+	queryBuilder := mysql.NewQueryBuilder(db).
+		Select("id, 'a?b' AS label").
+		From("hotel", "").
+		Where("name = :name AND stars IN (?, ?) AND created > ?", 4.5, true, created).
+		AndWhere("note = ? AND deleted IS ? AND code = ?", []byte("it's\n"), sql.NullString{}, []byte{0xff, 0x01}).
+		SetNamedParam("name", `O"Hara\`)
+
+	expectedSql := "SELECT id, 'a?b' AS label FROM `hotel` " +
+		`WHERE (name = 'O\"Hara\\' AND stars IN (4.5, 1) AND created > '2022-10-01 10:30:00') ` +
+		`AND (note = 'it\'s\n' AND deleted IS NULL AND code = X'ff01')`
+	assert.Equal(t, expectedSql, queryBuilder.DebugSQL())
+
+	stars := 4
+	var chain *string
+	pointers := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").Where("stars = ? AND chain <=> ? AND created > ?", &stars, chain, &created)
+	assert.Equal(t, "SELECT id FROM `hotel` WHERE stars = 4 AND chain <=> NULL AND created > '2022-10-01 10:30:00'", pointers.DebugSQL())
+
+	truncated := mysql.NewQueryBuilder(db).Update("hotel", "").Set("description", "a very long description").SetDebugValueLength(6)
+	assert.Equal(t, "UPDATE `hotel` SET `description` = 'a very...'", truncated.DebugSQL())
+}
+
+func Test_interpolate_sql(t *testing.T) {
+	assert.Equal(t, "SELECT 1 FROM dual WHERE a = 12 AND b = ?", mysql.InterpolateSQL("SELECT 1 FROM dual WHERE a = ? AND b = ?", []interface{}{12}, 0))
+	assert.Equal(t, "SELECT 1", mysql.InterpolateSQL("SELECT 1", nil, 0))
+}
@@ -0,0 +1,231 @@
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Dialect renders the parts of a query whose syntax differs between database engines.
+type Dialect interface {
+	// QuoteIdentifier returns a plain or qualified identifier with every part quoted,
+	// any other value is returned unchanged.
+	QuoteIdentifier(identifier string) string
+	// Placeholder returns the placeholder of the param at the given 1-based position.
+	Placeholder(position int) string
+	// Limit returns the limit string in SQL, preceded by a space, that skips offset rows and returns count rows.
+	Limit(offset int, count int) string
+	// InsertedValue returns the reference to the value an upsert would insert into the quoted column.
+	InsertedValue(column string) string
+	// Upsert returns the clause, preceded by a space, that applies the updates to the rows that would conflict with
+	// the quoted conflict columns.
+	Upsert(conflictColumns []string, updates []string) string
+	// Supports returns whether the dialect supports the MySQL specific feature, queries using unsupported features
+	// fail to build.
+	Supports(feature Feature) bool
+}
+
+// Feature is a MySQL specific feature that other dialects may not support.
+type Feature string
+
+// The MySQL specific features.
+const (
+	InsertIgnoreFeature    Feature = InsertIgnoreInto
+	ReplaceFeature         Feature = ReplaceInto
+	LockInShareModeFeature Feature = LockInShareMode
+	RowLimitFeature        Feature = "ORDER BY and LIMIT of update and delete queries"
+	SelectModifierFeature  Feature = "select modifiers other than DISTINCT"
+	OptimizerHintFeature   Feature = "optimizer hints"
+	IndexHintFeature       Feature = "index hints"
+	ExplainFeature         Feature = "tabular EXPLAIN"
+	LastInsertIdFeature    Feature = "LastInsertId"
+	UpsertAnyKeyFeature    Feature = "ON DUPLICATE KEY UPDATE without OnConflict columns"
+)
+
+type (
+	// MySQLDialect renders MySQL syntax, it is the default Dialect of QueryBuilder
+	MySQLDialect struct{}
+
+	// PostgresDialect renders PostgreSQL syntax
+	PostgresDialect struct{}
+)
+
+var (
+	_ Dialect = MySQLDialect{}
+	_ Dialect = PostgresDialect{}
+)
+
+// QuoteIdentifier returns the identifier quoted with backticks.
+func (MySQLDialect) QuoteIdentifier(identifier string) string {
+	return QuoteIdentifier(identifier)
+}
+
+// Placeholder returns ?.
+func (MySQLDialect) Placeholder(position int) string {
+	return "?"
+}
+
+// Limit returns LIMIT offset,count.
+func (MySQLDialect) Limit(offset int, count int) string {
+	return " LIMIT " + strconv.Itoa(offset) + "," + strconv.Itoa(count)
+}
+
+// InsertedValue returns VALUES(column).
+func (MySQLDialect) InsertedValue(column string) string {
+	return "VALUES(" + column + ")"
+}
+
+// Upsert returns ON DUPLICATE KEY UPDATE with the updates, MySQL checks every unique key so the conflict columns
+// are ignored.
+func (MySQLDialect) Upsert(conflictColumns []string, updates []string) string {
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
+
+// Supports returns true, every feature is MySQL specific.
+func (MySQLDialect) Supports(feature Feature) bool {
+	return true
+}
+
+// QuoteIdentifier returns the identifier quoted with double quotes, backticks are replaced.
+func (PostgresDialect) QuoteIdentifier(identifier string) string {
+	if !IsIdentifier(identifier) {
+		return identifier
+	}
+
+	parts := strings.Split(unquoteIdentifier(identifier), ".")
+
+	return `"` + strings.Join(parts, `"."`) + `"`
+}
+
+// Placeholder returns $position.
+func (PostgresDialect) Placeholder(position int) string {
+	return "$" + strconv.Itoa(position)
+}
+
+// Limit returns LIMIT count OFFSET offset.
+func (PostgresDialect) Limit(offset int, count int) string {
+	return " LIMIT " + strconv.Itoa(count) + " OFFSET " + strconv.Itoa(offset)
+}
+
+// InsertedValue returns EXCLUDED.column.
+func (PostgresDialect) InsertedValue(column string) string {
+	return "EXCLUDED." + column
+}
+
+// Upsert returns ON CONFLICT (conflict columns) DO UPDATE SET with the updates.
+func (PostgresDialect) Upsert(conflictColumns []string, updates []string) string {
+	return " ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// Supports returns false, as PostgreSQL has none of the MySQL specific features.
+func (PostgresDialect) Supports(feature Feature) bool {
+	return false
+}
+
+// SetDialect returns QueryBuilder that renders the query, and its subqueries, with the given dialect.
+func (queryBuilder *QueryBuilder) SetDialect(dialect Dialect) *QueryBuilder {
+	queryBuilder.dialect = dialect
+
+	return queryBuilder
+}
+
+// OnConflict returns QueryBuilder that sets the columns whose unique constraint triggers the updates set with
+// OnDuplicateKeyUpdate, as required by PostgreSQL. MySQL ignores them.
+func (queryBuilder *QueryBuilder) OnConflict(columns ...string) *QueryBuilder {
+	queryBuilder.conflictColumns = columns
+
+	return queryBuilder
+}
+
+// withDialect returns the QueryBuilder when it renders the given dialect already, or a clone rendering it otherwise.
+func (queryBuilder *QueryBuilder) withDialect(dialect Dialect) *QueryBuilder {
+	if sameDialect(queryBuilder.dialect, dialect) {
+		return queryBuilder
+	}
+
+	return queryBuilder.Clone().SetDialect(dialect)
+}
+
+// sameDialect returns whether both dialects are equal, dialects of types that are not comparable never are.
+func sameDialect(a Dialect, b Dialect) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+// validateDialect returns an error when the query uses a MySQL specific feature the dialect does not support.
+func (queryBuilder *QueryBuilder) validateDialect() error {
+	features := make([]Feature, 0)
+
+	switch queryBuilder.insertVerb {
+	case InsertIgnoreInto:
+		features = append(features, InsertIgnoreFeature)
+	case ReplaceInto:
+		features = append(features, ReplaceFeature)
+	}
+
+	if queryBuilder.lockMode == LockInShareMode {
+		features = append(features, LockInShareModeFeature)
+	}
+
+	if (queryBuilder.queryType == Update || queryBuilder.queryType == Delete) &&
+		(queryBuilder.isLimitQuery() || queryBuilder.hasSort == HasSort) {
+		features = append(features, RowLimitFeature)
+	}
+
+	for _, modifier := range queryBuilder.selectModifiers {
+		if modifier != Distinct {
+			features = append(features, SelectModifierFeature)
+			break
+		}
+	}
+
+	if len(queryBuilder.optimizerHints) > 0 {
+		features = append(features, OptimizerHintFeature)
+	}
+
+	if len(queryBuilder.sqlPartsIndexHint) > 0 {
+		features = append(features, IndexHintFeature)
+	}
+
+	if len(queryBuilder.sqlPartsOnDuplicate) > 0 && len(queryBuilder.conflictColumns) == 0 {
+		features = append(features, UpsertAnyKeyFeature)
+	}
+
+	for _, feature := range features {
+		if !queryBuilder.dialect.Supports(feature) {
+			return fmt.Errorf("%s is not supported by %T", feature, queryBuilder.dialect)
+		}
+	}
+
+	return nil
+}
+
+// rebind returns the SQL string with every ? placeholder replaced by the placeholder of the dialect.
+func rebind(dialect Dialect, sqlString string) string {
+	if dialect.Placeholder(1) == "?" {
+		return sqlString
+	}
+
+	var builder strings.Builder
+	position := 0
+
+	_ = walkPlaceholders(sqlString, func(chunk string, placeholder string) error {
+		builder.WriteString(chunk)
+
+		if placeholder != "?" {
+			builder.WriteString(placeholder)
+			return nil
+		}
+
+		position++
+		builder.WriteString(dialect.Placeholder(position))
+
+		return nil
+	})
+
+	return builder.String()
+}
@@ -0,0 +1,128 @@
+package mysql_test
+
+import (
+	"errors"
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
+	"testing"
+)
+
+func Test_postgres_dialect(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	reviewed := mysql.NewQueryBuilder(db).Select("hotel_id").From("review", "").Where("score > ?", 8)
+	queryBuilder := mysql.NewQueryBuilder(db).
+		SetDialect(mysql.PostgresDialect{}).
+		Select("h.id, h.name, '?' AS mark").
+		From("hotel", "h").
+		Where("h.city = :city").
+		AndWhereExpr(mysql.In("h.id", reviewed)).
+		AndWhere("h.opened::date < ? OR h.stars = ?", "2000-01-01", 5).
+		SetNamedParam("city", "BCN").
+		OrderBy("h.name", "ASC").
+		SetFirstResult(20).
+		SetMaxResults(10)
+
+	expectedSql := `SELECT h.id, h.name, '?' AS mark FROM "hotel" "h" ` +
+		`WHERE ((h.city = $1) AND (h.id IN (SELECT hotel_id FROM "review" WHERE score > $2))) ` +
+		`AND (h.opened::date < $3 OR h.stars = $4) ORDER BY "h"."name" ASC LIMIT 10 OFFSET 20`
+	assert.Equal(t, expectedSql, queryBuilder.GetSQL())
+	assert.Equal(t, []interface{}{"BCN", 8, "2000-01-01", 5}, queryBuilder.GetParameters())
+}
+
+func Test_postgres_upsert(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	upsert := mysql.NewQueryBuilder(db).
+		Insert("availability").
+		Columns("hotel_id", "day", "rooms").
+		AddRow(1, "2022-10-01", 3).
+		AddRow(2, "2022-10-01", 5).
+		OnDuplicateKeyUpdate("rooms").
+		OnDuplicateKeyUpdateExpr("updates", mysql.Expr("availability.updates + ?", 1)).
+		OnConflict("hotel_id", "day")
+
+	expectedMySQL := "INSERT INTO `availability` (`hotel_id`, `day`, `rooms`) VALUES (?, ?, ?), (?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `rooms` = VALUES(`rooms`), `updates` = availability.updates + ?"
+	assert.Equal(t, expectedMySQL, upsert.GetSQL())
+
+	expectedPostgres := `INSERT INTO "availability" ("hotel_id", "day", "rooms") VALUES ($1, $2, $3), ($4, $5, $6) ` +
+		`ON CONFLICT ("hotel_id", "day") DO UPDATE SET "rooms" = EXCLUDED."rooms", "updates" = availability.updates + $7`
+	assert.Equal(t, expectedPostgres, upsert.SetDialect(mysql.PostgresDialect{}).GetSQL())
+	assert.Equal(t, []interface{}{1, "2022-10-01", 3, 2, "2022-10-01", 5, 1}, upsert.GetParameters())
+}
+
+type customDialect struct {
+	mysql.PostgresDialect
+	reserved []string
+}
+
+func Test_postgres_unsupported_features(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	insertSql := regexp.QuoteMeta(`INSERT INTO "hotel" ("name") VALUES ($1)`)
+	mock.ExpectPrepare(insertSql)
+	mock.ExpectExec(insertSql).WithArgs("Arts").WillReturnResult(sqlmock.NewErrorResult(errors.New("no LastInsertId available")))
+
+	id, err2 := mysql.NewQueryBuilder(db).SetDialect(mysql.PostgresDialect{}).Insert("hotel").Value("name", "Arts").PrepareAndExecute()
+	assert.Nil(t, err2)
+	assert.Equal(t, int64(0), id)
+
+	postgres := func() *mysql.QueryBuilder {
+		return mysql.NewQueryBuilder(db).SetDialect(mysql.PostgresDialect{})
+	}
+
+	_, err3 := postgres().InsertIgnore("hotel").Value("name", "Arts").PrepareAndExecute()
+	assert.EqualError(t, err3, "INSERT IGNORE INTO is not supported by mysql.PostgresDialect")
+
+	_, err4 := postgres().Replace("hotel").Value("name", "Arts").PrepareAndExecute()
+	assert.EqualError(t, err4, "REPLACE INTO is not supported by mysql.PostgresDialect")
+
+	_, err5 := postgres().Select("id").From("hotel", "").LockInShareMode().QueryAssoc()
+	assert.EqualError(t, err5, "LOCK IN SHARE MODE is not supported by mysql.PostgresDialect")
+
+	_, err6 := postgres().Delete("hotel").Where("id > ?", 3).SetMaxResults(10).PrepareAndExecute()
+	assert.EqualError(t, err6, "ORDER BY and LIMIT of update and delete queries is not supported by mysql.PostgresDialect")
+
+	_, err7 := postgres().Select("id").From("hotel", "").SelectModifiers(mysql.SqlNoCache).QueryAssoc()
+	assert.EqualError(t, err7, "select modifiers other than DISTINCT is not supported by mysql.PostgresDialect")
+
+	_, err8 := postgres().Select("id").From("hotel", "").OptimizerHint("MAX_EXECUTION_TIME(1000)").QueryAssoc()
+	assert.EqualError(t, err8, "optimizer hints is not supported by mysql.PostgresDialect")
+
+	_, err9 := postgres().Select("id").From("hotel", "").UseIndex("hotel", "idx_name").QueryAssoc()
+	assert.EqualError(t, err9, "index hints is not supported by mysql.PostgresDialect")
+
+	_, err10 := postgres().Select("id").From("hotel", "").Explain()
+	assert.EqualError(t, err10, "tabular EXPLAIN is not supported by mysql.PostgresDialect")
+
+	_, err11 := postgres().Insert("hotel").Value("id", 1).Value("name", "Arts").OnDuplicateKeyUpdate("name").PrepareAndExecute()
+	assert.EqualError(t, err11, "ON DUPLICATE KEY UPDATE without OnConflict columns is not supported by mysql.PostgresDialect")
+
+	distinct := postgres().Select("id").From("hotel", "").Distinct().GetSQL()
+	assert.Equal(t, `SELECT DISTINCT id FROM "hotel"`, distinct)
+
+	err12 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err12, "there were unfulfilled expectations: %s", err12)
+}
+
+func Test_dialects_that_are_not_comparable(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	dialect := customDialect{reserved: []string{"user"}}
+	reviewed := mysql.NewQueryBuilder(db).SetDialect(dialect).Select("hotel_id").From("review", "").Where("score > ?", 8)
+	queryBuilder := mysql.NewQueryBuilder(db).
+		SetDialect(dialect).
+		Select("id").
+		From("hotel", "").
+		WhereExpr(mysql.In("id", reviewed))
+
+	expectedSql := `SELECT id FROM "hotel" WHERE id IN (SELECT hotel_id FROM "review" WHERE score > $1)`
+	assert.Equal(t, expectedSql, queryBuilder.GetSQL())
+}
@@ -0,0 +1,178 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// The plan warning reasons.
+const (
+	FullTableScan = "full table scan"
+	Filesort      = "filesort"
+)
+
+type (
+	// ExplainRow records a row of the tabular EXPLAIN output, the access plan of a table of the query
+	ExplainRow struct {
+		ID             int64
+		SelectType     string
+		Table          string
+		AccessType     string
+		PossibleKeys   []string
+		Key            string
+		KeyLength      string
+		Ref            string
+		Rows           int64
+		Filtered       float64
+		Extra          string
+		UsingFilesort  bool
+		UsingTemporary bool
+	}
+
+	// Plan records the access plan of every table of an explained query
+	Plan struct {
+		Rows []ExplainRow
+	}
+
+	// PlanWarning records a table of a query accessed with a full table scan or a filesort
+	PlanWarning struct {
+		Query  string
+		Reason string
+		Row    ExplainRow
+	}
+
+	// PlanWarningHook is called with every warning found in the plan of the queries executed in debug mode
+	PlanWarningHook func(warning PlanWarning)
+)
+
+// Explain runs EXPLAIN for the query being built and returns its plan.
+func (queryBuilder *QueryBuilder) Explain() (*Plan, error) {
+	return queryBuilder.ExplainContext(context.Background())
+}
+
+// ExplainContext runs EXPLAIN like Explain, the query is cancelled when the context is done.
+func (queryBuilder *QueryBuilder) ExplainContext(ctx context.Context) (*Plan, error) {
+	if !queryBuilder.dialect.Supports(ExplainFeature) {
+		return nil, fmt.Errorf("%s is not supported by %T", ExplainFeature, queryBuilder.dialect)
+	}
+
+	query, params, err := queryBuilder.build()
+	if err != nil {
+		return nil, err
+	}
+
+	return explain(ctx, queryBuilder.database, query, params)
+}
+
+// SetPlanWarningHook returns QueryBuilder in debug mode, every select, update and delete query it executes is
+// explained first and the hook is called for each table accessed with a full table scan or a filesort examining
+// more than rowsThreshold rows. It is meant for development and staging, as it runs every query twice.
+func (queryBuilder *QueryBuilder) SetPlanWarningHook(rowsThreshold int64, hook PlanWarningHook) *QueryBuilder {
+	queryBuilder.planRowsThreshold = rowsThreshold
+	queryBuilder.planWarningHook = hook
+
+	return queryBuilder
+}
+
+// LogPlanWarning is a PlanWarningHook that logs the warning with the standard logger.
+func LogPlanWarning(warning PlanWarning) {
+	log.Printf("mysql: %s of table %s examining %d rows - query: %s",
+		warning.Reason, warning.Row.Table, warning.Row.Rows, warning.Query)
+}
+
+// Warnings returns the tables accessed with a full table scan or a filesort examining more than rowsThreshold rows.
+func (plan *Plan) Warnings(query string, rowsThreshold int64) []PlanWarning {
+	warnings := make([]PlanWarning, 0)
+
+	for _, row := range plan.Rows {
+		if row.Rows <= rowsThreshold {
+			continue
+		}
+
+		if row.AccessType == "ALL" {
+			warnings = append(warnings, PlanWarning{Query: query, Reason: FullTableScan, Row: row})
+		}
+
+		if row.UsingFilesort {
+			warnings = append(warnings, PlanWarning{Query: query, Reason: Filesort, Row: row})
+		}
+	}
+
+	return warnings
+}
+
+// checkPlan explains the query and calls the plan warning hook with every warning found, explain errors are ignored
+// as the query itself will report them.
+func (queryBuilder *QueryBuilder) checkPlan(ctx context.Context, query string, params []interface{}) {
+	if queryBuilder.planWarningHook == nil || !queryBuilder.dialect.Supports(ExplainFeature) {
+		return
+	}
+
+	plan, err := explain(ctx, queryBuilder.database, query, params)
+	if err != nil {
+		return
+	}
+
+	for _, warning := range plan.Warnings(InterpolateSQL(query, params, queryBuilder.debugValueLength), queryBuilder.planRowsThreshold) {
+		queryBuilder.planWarningHook(warning)
+	}
+}
+
+// explain runs the tabular EXPLAIN of the query and returns its plan, the columns missing in older MySQL versions
+// are left empty.
+func explain(ctx context.Context, database Executor, query string, params []interface{}) (*Plan, error) {
+	rows, err := database.QueryContext(ctx, "EXPLAIN "+query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsMap, err := getRowsMap(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Rows: make([]ExplainRow, 0, len(rowsMap))}
+	for i := 0; i < len(rowsMap); i++ {
+		plan.Rows = append(plan.Rows, newExplainRow(rowsMap[i]))
+	}
+
+	return plan, nil
+}
+
+// newExplainRow returns the ExplainRow of a row of the tabular EXPLAIN output.
+func newExplainRow(row map[string]Field) ExplainRow {
+	column := func(name string) string {
+		field, ok := row[name]
+		if !ok || field.RawVal() == nil {
+			return ""
+		}
+
+		return toString(field.RawVal())
+	}
+
+	explainRow := ExplainRow{
+		SelectType: column("select_type"),
+		Table:      column("table"),
+		AccessType: column("type"),
+		Key:        column("key"),
+		KeyLength:  column("key_len"),
+		Ref:        column("ref"),
+		Extra:      column("Extra"),
+	}
+
+	explainRow.ID, _ = strconv.ParseInt(column("id"), 10, 64)
+	explainRow.Rows, _ = strconv.ParseInt(column("rows"), 10, 64)
+	explainRow.Filtered, _ = strconv.ParseFloat(column("filtered"), 64)
+
+	if possibleKeys := column("possible_keys"); possibleKeys != "" {
+		explainRow.PossibleKeys = strings.Split(possibleKeys, ",")
+	}
+
+	explainRow.UsingFilesort = strings.Contains(explainRow.Extra, "Using filesort")
+	explainRow.UsingTemporary = strings.Contains(explainRow.Extra, "Using temporary")
+
+	return explainRow
+}
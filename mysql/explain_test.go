@@ -0,0 +1,96 @@
+package mysql_test
+
+import (
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
+	"testing"
+)
+
+var explainColumns = []string{"id", "select_type", "table", "partitions", "type", "possible_keys", "key", "key_len", "ref", "rows", "filtered", "Extra"}
+
+func Test_explain(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	explainSql := "EXPLAIN SELECT h.id FROM `hotel` `h` INNER JOIN `city` `c` ON c.id = h.city_id WHERE c.country = ? ORDER BY `h`.`name` ASC"
+	rows := sqlmock.NewRows(explainColumns).
+		AddRow("1", "SIMPLE", "c", nil, "ref", "PRIMARY,idx_country", "idx_country", "8", "const", "12", "100.00", "Using index; Using temporary; Using filesort").
+		AddRow("1", "SIMPLE", "h", nil, "ALL", nil, nil, nil, nil, "48000", "10.00", "Using where; Using join buffer (hash join)")
+	mock.ExpectQuery(regexp.QuoteMeta(explainSql)).WithArgs("ES").WillReturnRows(rows)
+
+	plan, err2 := mysql.NewQueryBuilder(db).
+		Select("h.id").
+		From("hotel", "h").
+		InnerJoin("city", "c", "c.id = h.city_id").
+		Where("c.country = ?", "ES").
+		OrderBy("h.name", "ASC").
+		Explain()
+	assert.Nil(t, err2)
+	assert.Len(t, plan.Rows, 2)
+
+	assert.Equal(t, "ref", plan.Rows[0].AccessType)
+	assert.Equal(t, []string{"PRIMARY", "idx_country"}, plan.Rows[0].PossibleKeys)
+	assert.Equal(t, "idx_country", plan.Rows[0].Key)
+	assert.True(t, plan.Rows[0].UsingFilesort)
+	assert.True(t, plan.Rows[0].UsingTemporary)
+	assert.Equal(t, int64(48000), plan.Rows[1].Rows)
+	assert.Equal(t, 10.0, plan.Rows[1].Filtered)
+	assert.Nil(t, plan.Rows[1].PossibleKeys)
+
+	warnings := plan.Warnings("query", 1000)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, mysql.FullTableScan, warnings[0].Reason)
+	assert.Equal(t, "h", warnings[0].Row.Table)
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_plan_warning_hook(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(regexp.QuoteMeta("EXPLAIN SELECT id FROM `audit` ORDER BY `created` DESC")).
+		WillReturnRows(sqlmock.NewRows(explainColumns).
+			AddRow("1", "SIMPLE", "audit", nil, "ALL", nil, nil, nil, nil, "250000", "100.00", "Using filesort"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM `audit` ORDER BY `created` DESC")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var warnings []mysql.PlanWarning
+	connection := mysql.NewConnectionFromDB(db)
+	connection.SetPlanWarningHook(10000, func(warning mysql.PlanWarning) {
+		warnings = append(warnings, warning)
+	})
+
+	result, err2 := connection.NewQueryBuilder().Select("id").From("audit", "").OrderBy("created", "DESC").QueryAssoc()
+	assert.Nil(t, err2)
+	assert.Len(t, result, 1)
+
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, mysql.FullTableScan, warnings[0].Reason)
+	assert.Equal(t, mysql.Filesort, warnings[1].Reason)
+	assert.Equal(t, "SELECT id FROM `audit` ORDER BY `created` DESC", warnings[1].Query)
+
+	updateSql := "UPDATE `audit` SET `archived` = ? WHERE created < ?"
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("EXPLAIN "+updateSql)).WithArgs(1, "2022-01-01").
+		WillReturnRows(sqlmock.NewRows(explainColumns).
+			AddRow("1", "UPDATE", "audit", nil, "ALL", nil, nil, nil, nil, "250000", "100.00", "Using where"))
+	mock.ExpectPrepare(regexp.QuoteMeta(updateSql)).WillBeClosed()
+	mock.ExpectExec(regexp.QuoteMeta(updateSql)).WithArgs(1, "2022-01-01").WillReturnResult(sqlmock.NewResult(0, 3))
+
+	tx, err3 := db.Begin()
+	assert.Nil(t, err3)
+
+	affected, err4 := connection.NewQueryBuilderTx(tx).Update("audit", "").Set("archived", 1).Where("created < ?", "2022-01-01").PrepareAndExecute()
+	assert.Nil(t, err4)
+	assert.Equal(t, int64(3), affected)
+
+	assert.Len(t, warnings, 3)
+	assert.Equal(t, "UPDATE `audit` SET `archived` = 1 WHERE created < '2022-01-01'", warnings[2].Query)
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
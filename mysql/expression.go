@@ -0,0 +1,189 @@
+package mysql
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Expression is a SQL condition fragment together with the parameters bound to its placeholders.
+type Expression struct {
+	sql    string
+	params []interface{}
+}
+
+// Expr returns an Expression built from a raw condition and the parameters of its placeholders.
+func Expr(condition string, params ...interface{}) Expression {
+	return Expression{sql: bindPlaceholders(condition, len(params)), params: params}
+}
+
+// GetSQL returns the SQL fragment of the expression.
+func (e Expression) GetSQL() string {
+	return strings.ReplaceAll(e.sql, boundPlaceholder, "?")
+}
+
+// GetParameters returns the parameters bound to the expression placeholders in order.
+func (e Expression) GetParameters() []interface{} {
+	return e.params
+}
+
+// IsEmpty returns whether the expression has no condition at all.
+func (e Expression) IsEmpty() bool {
+	return strings.TrimSpace(e.sql) == ""
+}
+
+// Eq returns an Expression that checks column = value.
+func Eq(column string, value interface{}) Expression {
+	return comparison(column, "=", value)
+}
+
+// Neq returns an Expression that checks column <> value.
+func Neq(column string, value interface{}) Expression {
+	return comparison(column, "<>", value)
+}
+
+// Lt returns an Expression that checks column < value.
+func Lt(column string, value interface{}) Expression {
+	return comparison(column, "<", value)
+}
+
+// Lte returns an Expression that checks column <= value.
+func Lte(column string, value interface{}) Expression {
+	return comparison(column, "<=", value)
+}
+
+// Gt returns an Expression that checks column > value.
+func Gt(column string, value interface{}) Expression {
+	return comparison(column, ">", value)
+}
+
+// Gte returns an Expression that checks column >= value.
+func Gte(column string, value interface{}) Expression {
+	return comparison(column, ">=", value)
+}
+
+// Like returns an Expression that checks column LIKE pattern.
+func Like(column string, pattern interface{}) Expression {
+	return comparison(column, "LIKE", pattern)
+}
+
+// NotLike returns an Expression that checks column NOT LIKE pattern.
+func NotLike(column string, pattern interface{}) Expression {
+	return comparison(column, "NOT LIKE", pattern)
+}
+
+// IsNull returns an Expression that checks column IS NULL.
+func IsNull(column string) Expression {
+	return Expr(column + " IS NULL")
+}
+
+// IsNotNull returns an Expression that checks column IS NOT NULL.
+func IsNotNull(column string) Expression {
+	return Expr(column + " IS NOT NULL")
+}
+
+// Between returns an Expression that checks column BETWEEN from AND to.
+func Between(column string, from interface{}, to interface{}) Expression {
+	return Expr(column+" BETWEEN ? AND ?", from, to)
+}
+
+// In returns an Expression that checks column IN (values).
+// A single slice value is expanded, a single QueryBuilder value is used as subquery and an empty list never matches.
+func In(column string, values ...interface{}) Expression {
+	return inList(column, "IN", "1 = 0", values)
+}
+
+// NotIn returns an Expression that checks column NOT IN (values).
+// A single slice value is expanded, a single QueryBuilder value is used as subquery and an empty list always matches.
+func NotIn(column string, values ...interface{}) Expression {
+	return inList(column, "NOT IN", "1 = 1", values)
+}
+
+// Exists returns an Expression that checks the subquery returns any row.
+func Exists(subQuery *QueryBuilder) Expression {
+	return Expr("EXISTS ?", subQuery)
+}
+
+// NotExists returns an Expression that checks the subquery returns no rows.
+func NotExists(subQuery *QueryBuilder) Expression {
+	return Expr("NOT EXISTS ?", subQuery)
+}
+
+// And returns an Expression that joins the non empty expressions with AND.
+func And(expressions ...Expression) Expression {
+	return composite("AND", expressions)
+}
+
+// Or returns an Expression that joins the non empty expressions with OR.
+func Or(expressions ...Expression) Expression {
+	return composite("OR", expressions)
+}
+
+// comparison returns an Expression that compares column against a single bound value,
+// a QueryBuilder value is used as scalar subquery.
+func comparison(column string, operator string, value interface{}) Expression {
+	return Expr(column+" "+operator+" ?", value)
+}
+
+// inList returns an Expression for IN and NOT IN lists, using whenEmpty when there are no values.
+func inList(column string, operator string, whenEmpty string, values []interface{}) Expression {
+	if len(values) == 1 {
+		if subQuery, ok := values[0].(*QueryBuilder); ok {
+			return Expr(column+" "+operator+" ?", subQuery)
+		}
+	}
+
+	values = expandValues(values)
+	if len(values) == 0 {
+		return Expr(whenEmpty)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+
+	return Expr(column+" "+operator+" ("+placeholders+")", values...)
+}
+
+// composite returns the expressions wrapped in parentheses and joined by the given conjunction.
+func composite(conjunction string, expressions []Expression) Expression {
+	parts := make([]Expression, 0, len(expressions))
+	for _, e := range expressions {
+		if !e.IsEmpty() {
+			parts = append(parts, e)
+		}
+	}
+
+	if len(parts) == 1 {
+		return parts[0]
+	}
+
+	conditions := make([]string, 0, len(parts))
+	params := make([]interface{}, 0)
+	for _, e := range parts {
+		conditions = append(conditions, "("+e.sql+")")
+		params = append(params, e.params...)
+	}
+
+	return Expression{sql: strings.Join(conditions, " "+conjunction+" "), params: params}
+}
+
+// expandValues expands a single slice value into its elements, byte slices are kept as a single value.
+func expandValues(values []interface{}) []interface{} {
+	if len(values) != 1 || values[0] == nil {
+		return values
+	}
+
+	if _, isBytes := values[0].([]byte); isBytes {
+		return values
+	}
+
+	v := reflect.ValueOf(values[0])
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return values
+	}
+
+	expanded := make([]interface{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		expanded[i] = v.Index(i).Interface()
+	}
+
+	return expanded
+}
@@ -0,0 +1,58 @@
+package mysql_test
+
+import (
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_comparison_expressions(t *testing.T) {
+	cases := map[string]mysql.Expression{
+		"id = ?":        mysql.Eq("id", 1),
+		"id <> ?":       mysql.Neq("id", 1),
+		"id < ?":        mysql.Lt("id", 1),
+		"id <= ?":       mysql.Lte("id", 1),
+		"id > ?":        mysql.Gt("id", 1),
+		"id >= ?":       mysql.Gte("id", 1),
+		"id LIKE ?":     mysql.Like("id", 1),
+		"id NOT LIKE ?": mysql.NotLike("id", 1),
+	}
+
+	for expectedSql, expression := range cases {
+		assert.Equal(t, expectedSql, expression.GetSQL())
+		assert.Equal(t, []interface{}{1}, expression.GetParameters())
+	}
+
+	assert.Equal(t, "deleted_at IS NULL", mysql.IsNull("deleted_at").GetSQL())
+	assert.Equal(t, "deleted_at IS NOT NULL", mysql.IsNotNull("deleted_at").GetSQL())
+
+	between := mysql.Between("price", 10, 20)
+	assert.Equal(t, "price BETWEEN ? AND ?", between.GetSQL())
+	assert.Equal(t, []interface{}{10, 20}, between.GetParameters())
+}
+
+func Test_in_expressions(t *testing.T) {
+	in := mysql.In("id", 1, 2, 3)
+	assert.Equal(t, "id IN (?, ?, ?)", in.GetSQL())
+	assert.Equal(t, []interface{}{1, 2, 3}, in.GetParameters())
+
+	expanded := mysql.NotIn("code", []string{"BCN", "MAD"})
+	assert.Equal(t, "code NOT IN (?, ?)", expanded.GetSQL())
+	assert.Equal(t, []interface{}{"BCN", "MAD"}, expanded.GetParameters())
+
+	assert.Equal(t, "1 = 0", mysql.In("id", []int{}).GetSQL())
+	assert.Equal(t, "1 = 1", mysql.NotIn("id").GetSQL())
+}
+
+func Test_composite_expressions(t *testing.T) {
+	expression := mysql.And(
+		mysql.Eq("status", "confirmed"),
+		mysql.Expr(""),
+		mysql.Or(mysql.Gt("price", 100), mysql.IsNull("price")),
+	)
+
+	assert.Equal(t, "(status = ?) AND ((price > ?) OR (price IS NULL))", expression.GetSQL())
+	assert.Equal(t, []interface{}{"confirmed", 100}, expression.GetParameters())
+	assert.Equal(t, "id = ?", mysql.Or(mysql.Eq("id", 1)).GetSQL())
+	assert.True(t, mysql.And().IsEmpty())
+}
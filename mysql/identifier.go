@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// identifierPattern matches plain and qualified identifiers, either bare or quoted with backticks.
+var identifierPattern = regexp.MustCompile("^(`[^`.]+`|[A-Za-z_][A-Za-z0-9_$]*)(\\.(`[^`.]+`|[A-Za-z_][A-Za-z0-9_$]*)){0,2}$")
+
+// InvalidIdentifierError is returned when a value used as identifier or sort order is not safe to render,
+// either because it is not a plain or qualified column name or because it is not allowed in the query.
+type InvalidIdentifierError struct {
+	Identifier string
+	Reason     string
+}
+
+// Error returns the error message.
+func (e *InvalidIdentifierError) Error() string {
+	return fmt.Sprintf("invalid identifier %q: %s", e.Identifier, e.Reason)
+}
+
+// IsIdentifier returns whether the value is a plain or qualified identifier such as column, table.column
+// or schema.table.column.
+func IsIdentifier(identifier string) bool {
+	return identifierPattern.MatchString(identifier)
+}
+
+// ValidateIdentifier returns an InvalidIdentifierError when the value is not a plain or qualified identifier.
+func ValidateIdentifier(identifier string) error {
+	if !IsIdentifier(identifier) {
+		return &InvalidIdentifierError{Identifier: identifier, Reason: "not a plain or qualified column name"}
+	}
+
+	return nil
+}
+
+// QuoteIdentifier returns a plain or qualified identifier with every part quoted with backticks,
+// any other value, such as an expression or a derived table, is returned unchanged.
+func QuoteIdentifier(identifier string) string {
+	if !IsIdentifier(identifier) {
+		return identifier
+	}
+
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "`") {
+			parts[i] = "`" + part + "`"
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// unquoteIdentifier returns the identifier without backticks.
+func unquoteIdentifier(identifier string) string {
+	return strings.ReplaceAll(identifier, "`", "")
+}
+
+// normalizeOrder returns the upper cased sort order, defaulting to ASC, or an error when it is not ASC nor DESC.
+func normalizeOrder(order string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(order)) {
+	case "", "ASC":
+		return "ASC", nil
+	case "DESC":
+		return "DESC", nil
+	}
+
+	return "", &InvalidIdentifierError{Identifier: order, Reason: "sort order must be ASC or DESC"}
+}
@@ -0,0 +1,76 @@
+package mysql_test
+
+import (
+	"errors"
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+)
+
+func Test_quote_identifier(t *testing.T) {
+	cases := map[string]string{
+		"id":             "`id`",
+		"h.id":           "`h`.`id`",
+		"db.hotel.id":    "`db`.`hotel`.`id`",
+		"`h`.name":       "`h`.`name`",
+		"COUNT(*)":       "COUNT(*)",
+		"id; DROP TABLE": "id; DROP TABLE",
+	}
+
+	for identifier, expected := range cases {
+		assert.Equal(t, expected, mysql.QuoteIdentifier(identifier))
+	}
+}
+
+func Test_validate_identifier(t *testing.T) {
+	assert.Nil(t, mysql.ValidateIdentifier("h.created_at"))
+
+	var invalid *mysql.InvalidIdentifierError
+	err := mysql.ValidateIdentifier("created_at DESC, (SELECT 1)")
+	assert.True(t, errors.As(err, &invalid))
+	assert.Equal(t, "created_at DESC, (SELECT 1)", invalid.Identifier)
+}
+
+func Test_order_by_rejects_unsafe_input(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	var invalid *mysql.InvalidIdentifierError
+
+	queryBuilder := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").OrderBy("id; DROP TABLE hotel", "ASC")
+	assert.True(t, errors.As(queryBuilder.Err(), &invalid))
+
+	_, err2 := queryBuilder.QueryAssoc()
+	assert.True(t, errors.As(err2, &invalid))
+
+	queryBuilder = mysql.NewQueryBuilder(db).Select("id").From("hotel", "").OrderBy("id", "ASC, name")
+	assert.True(t, errors.As(queryBuilder.Err(), &invalid))
+
+	queryBuilder = mysql.NewQueryBuilder(db).Select("id").From("hotel", "").OrderBy("name", "desc")
+	assert.Nil(t, queryBuilder.Err())
+	assert.Equal(t, "SELECT id FROM `hotel` ORDER BY `name` DESC", queryBuilder.GetSQL())
+}
+
+func Test_allowed_identifiers(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db).
+		AllowIdentifiers("name", "stars").
+		Select("id").
+		From("hotel", "").
+		OrderBy("stars", "DESC").
+		GroupBy("name, stars")
+	assert.Nil(t, queryBuilder.Err())
+	assert.Equal(t, "SELECT id FROM `hotel` GROUP BY `name`, `stars` ORDER BY `stars` DESC", queryBuilder.GetSQL())
+
+	var invalid *mysql.InvalidIdentifierError
+	queryBuilder = mysql.NewQueryBuilder(db).
+		AllowIdentifiers("name", "stars").
+		Select("id").
+		From("hotel", "").
+		OrderBy("password", "ASC")
+	assert.True(t, errors.As(queryBuilder.Err(), &invalid))
+	assert.Equal(t, "password", invalid.Identifier)
+}
@@ -0,0 +1,20 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Executor runs the statements of a QueryBuilder, it is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+var (
+	_ Executor = (*sql.DB)(nil)
+	_ Executor = (*sql.Tx)(nil)
+	_ Executor = (*sql.Conn)(nil)
+)
@@ -0,0 +1,593 @@
+package mysql
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMigrationsTable is the default table recording the applied migrations.
+const DefaultMigrationsTable = "schema_migrations"
+
+// DefaultMigrationLockTimeout is the default time a Migrator waits for the migration lock.
+const DefaultMigrationLockTimeout = time.Minute
+
+// errNoSuchTable is the MySQL error number of a missing table.
+const errNoSuchTable = 1146
+
+// migrationLockName is the SQL expression of the migration lock name of a migrations table in the current database,
+// hashed as MySQL limits lock names to 64 characters.
+const migrationLockName = "SHA2(CONCAT(DATABASE(), '.', ?), 256)"
+
+// ErrMigrationLocked is returned when the migration lock is held by another process beyond the lock timeout.
+var ErrMigrationLocked = errors.New("migration lock is held by another process")
+
+// ErrMigrationDirty is returned when a previous migration failed partway, leaving the schema in an unknown state.
+var ErrMigrationDirty = errors.New("schema was left dirty")
+
+// migrationFilePattern matches the migration file names, such as 20240131120000_create_hotels.up.sql.
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type (
+	// Migration records a version of the schema, the SQL scripts that apply and revert it and the checksum of the
+	// up script
+	Migration struct {
+		Version  int64
+		Name     string
+		Up       string
+		Down     string
+		Checksum string
+	}
+
+	// MigrationStatus records a migration and whether it is applied. Modified migrations were changed after being
+	// applied, missing ones were applied but have no files and dirty ones failed partway while applied or reverted.
+	MigrationStatus struct {
+		Migration
+		Applied   bool
+		AppliedAt time.Time
+		Modified  bool
+		Missing   bool
+		Dirty     bool
+	}
+
+	// Migrator applies and reverts the migrations of a Connection, holding a named lock so that a single process
+	// migrates the database at a time
+	Migrator struct {
+		db          *sql.DB
+		migrations  []Migration
+		table       string
+		lockTimeout time.Duration
+		dryRun      bool
+		err         error
+	}
+
+	// appliedMigration records a row of the migrations table
+	appliedMigration struct {
+		Version   int64     `db:"version"`
+		Name      string    `db:"name"`
+		Checksum  string    `db:"checksum"`
+		AppliedAt time.Time `db:"applied_at"`
+		Dirty     bool      `db:"dirty"`
+	}
+
+	// migrationStep records a migration to apply, or to revert when it is not up
+	migrationStep struct {
+		migration Migration
+		up        bool
+	}
+)
+
+// NewMigrator returns a Migrator of the migrations found in the directory of the file system, usually an embed.FS.
+func (c *Connection) NewMigrator(migrations fs.FS, dir string) (*Migrator, error) {
+	loaded, err := LoadMigrations(migrations, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:          c.db,
+		migrations:  loaded,
+		table:       DefaultMigrationsTable,
+		lockTimeout: DefaultMigrationLockTimeout,
+	}, nil
+}
+
+// LoadMigrations returns the migrations of the directory ordered by version. Every migration has an up file,
+// named like 20240131120000_create_hotels.up.sql, and an optional down file, named like the up file with .down.sql.
+// Files other than .sql files are ignored.
+func LoadMigrations(migrations fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	hasUp := map[int64]bool{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named like VERSION_NAME.up.sql or VERSION_NAME.down.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s has an invalid version: %w", entry.Name(), err)
+		}
+
+		script, err := fs.ReadFile(migrations, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(script)
+			hasUp[version] = true
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	loaded := make([]Migration, 0, len(byVersion))
+	for version, migration := range byVersion {
+		if !hasUp[version] {
+			return nil, fmt.Errorf("migration %d_%s has no up file", version, migration.Name)
+		}
+
+		checksum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(checksum[:])
+		loaded = append(loaded, *migration)
+	}
+
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].Version < loaded[j].Version
+	})
+
+	return loaded, nil
+}
+
+// SetTable returns Migrator that records the applied migrations in the given table.
+func (migrator *Migrator) SetTable(table string) *Migrator {
+	if err := ValidateIdentifier(table); err != nil && migrator.err == nil {
+		migrator.err = err
+	}
+	migrator.table = table
+
+	return migrator
+}
+
+// SetLockTimeout returns Migrator that waits up to the given timeout for the migration lock, rounded down to
+// seconds.
+func (migrator *Migrator) SetLockTimeout(timeout time.Duration) *Migrator {
+	migrator.lockTimeout = timeout
+
+	return migrator
+}
+
+// SetDryRun returns Migrator that only returns the migrations Up, Down and To would apply or revert, without
+// running them, taking the lock or creating the migrations table.
+func (migrator *Migrator) SetDryRun(dryRun bool) *Migrator {
+	migrator.dryRun = dryRun
+
+	return migrator
+}
+
+// Migrations returns the migrations of the Migrator ordered by version.
+func (migrator *Migrator) Migrations() []Migration {
+	return migrator.migrations
+}
+
+// Up applies every pending migration in version order and returns them.
+func (migrator *Migrator) Up() ([]Migration, error) {
+	return migrator.UpContext(context.Background())
+}
+
+// UpContext applies the pending migrations like Up, the migration is cancelled when the context is done.
+func (migrator *Migrator) UpContext(ctx context.Context) ([]Migration, error) {
+	return migrator.run(ctx, func(applied map[int64]appliedMigration) ([]migrationStep, error) {
+		return migrator.planUp(applied, -1), nil
+	})
+}
+
+// Down reverts the last applied migration and returns it, it returns no migration when none is applied.
+func (migrator *Migrator) Down() ([]Migration, error) {
+	return migrator.DownContext(context.Background())
+}
+
+// DownContext reverts the last applied migration like Down, the migration is cancelled when the context is done.
+func (migrator *Migrator) DownContext(ctx context.Context) ([]Migration, error) {
+	return migrator.run(ctx, func(applied map[int64]appliedMigration) ([]migrationStep, error) {
+		versions := appliedVersions(applied)
+		if len(versions) == 0 {
+			return nil, nil
+		}
+
+		previous := int64(0)
+		if len(versions) > 1 {
+			previous = versions[len(versions)-2]
+		}
+
+		return migrator.planDown(applied, previous)
+	})
+}
+
+// To reverts the applied migrations beyond the given version and applies the pending migrations up to it, in that
+// order, and returns them. Version 0 reverts every migration.
+func (migrator *Migrator) To(version int64) ([]Migration, error) {
+	return migrator.ToContext(context.Background(), version)
+}
+
+// ToContext migrates to the given version like To, the migration is cancelled when the context is done.
+func (migrator *Migrator) ToContext(ctx context.Context, version int64) ([]Migration, error) {
+	if _, ok := migrator.find(version); !ok && version != 0 {
+		return nil, fmt.Errorf("migration version %d does not exist", version)
+	}
+
+	return migrator.run(ctx, func(applied map[int64]appliedMigration) ([]migrationStep, error) {
+		down, err := migrator.planDown(applied, version)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(down, migrator.planUp(applied, version)...), nil
+	})
+}
+
+// Status returns the status of every migration, and of the applied migrations that have no files, ordered by
+// version.
+func (migrator *Migrator) Status() ([]MigrationStatus, error) {
+	return migrator.StatusContext(context.Background())
+}
+
+// StatusContext returns the status of the migrations like Status, the query is cancelled when the context is done.
+func (migrator *Migrator) StatusContext(ctx context.Context) ([]MigrationStatus, error) {
+	if migrator.err != nil {
+		return nil, migrator.err
+	}
+
+	applied, err := migrator.applied(ctx, migrator.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		status := MigrationStatus{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = row.AppliedAt
+			status.Modified = row.Checksum != migration.Checksum
+			status.Dirty = row.Dirty
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, version := range appliedVersions(applied) {
+		if _, ok := migrator.find(version); !ok {
+			row := applied[version]
+			statuses = append(statuses, MigrationStatus{
+				Migration: Migration{Version: version, Name: row.Name, Checksum: row.Checksum},
+				Applied:   true,
+				AppliedAt: row.AppliedAt,
+				Missing:   true,
+				Dirty:     row.Dirty,
+			})
+		}
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// run applies the steps planned from the applied migrations while holding the migration lock, and returns the
+// migrations of the steps that succeeded. In dry run mode the steps are only planned.
+func (migrator *Migrator) run(ctx context.Context, plan func(applied map[int64]appliedMigration) ([]migrationStep, error)) ([]Migration, error) {
+	if migrator.err != nil {
+		return nil, migrator.err
+	}
+
+	if migrator.dryRun {
+		applied, err := migrator.applied(ctx, migrator.db)
+		if err != nil {
+			return nil, err
+		}
+
+		steps, err := migrator.check(applied, plan)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations := make([]Migration, 0, len(steps))
+		for _, step := range steps {
+			migrations = append(migrations, step.migration)
+		}
+
+		return migrations, nil
+	}
+
+	conn, err := migrator.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := migrator.lock(ctx, conn); err != nil {
+		return nil, err
+	}
+	defer migrator.unlock(conn)
+
+	if _, err := conn.ExecContext(ctx, migrator.getSQLForCreateTable()); err != nil {
+		return nil, fmt.Errorf("Error %w when creating the migrations table %s", err, migrator.table)
+	}
+
+	applied, err := migrator.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := migrator.check(applied, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(steps))
+	for _, step := range steps {
+		if err := migrator.apply(ctx, conn, step); err != nil {
+			return migrations, err
+		}
+		migrations = append(migrations, step.migration)
+	}
+
+	return migrations, nil
+}
+
+// check returns the planned steps, or an error when a migration failed partway or an applied migration was modified
+// after being applied.
+func (migrator *Migrator) check(applied map[int64]appliedMigration, plan func(applied map[int64]appliedMigration) ([]migrationStep, error)) ([]migrationStep, error) {
+	for _, version := range appliedVersions(applied) {
+		if row := applied[version]; row.Dirty {
+			return nil, fmt.Errorf("%w by migration %d_%s, repair the schema and its row in the migrations table %s",
+				ErrMigrationDirty, version, row.Name, migrator.table)
+		}
+	}
+
+	for _, migration := range migrator.migrations {
+		if row, ok := applied[migration.Version]; ok && row.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("migration %d_%s was modified after being applied", migration.Version, migration.Name)
+		}
+	}
+
+	return plan(applied)
+}
+
+// planUp returns the steps applying the pending migrations up to the given version, or every one when it is -1.
+func (migrator *Migrator) planUp(applied map[int64]appliedMigration, version int64) []migrationStep {
+	steps := make([]migrationStep, 0)
+
+	for _, migration := range migrator.migrations {
+		if version != -1 && migration.Version > version {
+			break
+		}
+		if _, ok := applied[migration.Version]; !ok {
+			steps = append(steps, migrationStep{migration: migration, up: true})
+		}
+	}
+
+	return steps
+}
+
+// planDown returns the steps reverting the applied migrations beyond the given version, from the last one.
+func (migrator *Migrator) planDown(applied map[int64]appliedMigration, version int64) ([]migrationStep, error) {
+	steps := make([]migrationStep, 0)
+	versions := appliedVersions(applied)
+
+	for i := len(versions) - 1; i >= 0 && versions[i] > version; i-- {
+		migration, ok := migrator.find(versions[i])
+		if !ok {
+			return nil, fmt.Errorf("applied migration %d_%s has no files to revert it", versions[i], applied[versions[i]].Name)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down file and can not be reverted", migration.Version, migration.Name)
+		}
+
+		steps = append(steps, migrationStep{migration: migration, up: false})
+	}
+
+	return steps, nil
+}
+
+// apply runs the statements of the step and records it in the migrations table. The migration is recorded as dirty
+// while its statements run, so that a migration failing partway stops the next runs until it is repaired.
+func (migrator *Migrator) apply(ctx context.Context, conn *sql.Conn, step migrationStep) error {
+	table := QuoteIdentifier(migrator.table)
+	script, direction := step.migration.Up, "up"
+	begin := "INSERT INTO " + table + " (`version`, `name`, `checksum`, `applied_at`, `dirty`) VALUES (?, ?, ?, NOW(), 1)"
+	beginParams := []interface{}{step.migration.Version, step.migration.Name, step.migration.Checksum}
+	end := "UPDATE " + table + " SET `applied_at` = NOW(), `dirty` = 0 WHERE `version` = ?"
+	if !step.up {
+		script, direction = step.migration.Down, "down"
+		begin = "UPDATE " + table + " SET `dirty` = 1 WHERE `version` = ?"
+		beginParams = []interface{}{step.migration.Version}
+		end = "DELETE FROM " + table + " WHERE `version` = ?"
+	}
+
+	if _, err := conn.ExecContext(ctx, begin, beginParams...); err != nil {
+		return fmt.Errorf("Error %w when recording migration %d_%s %s", err, step.migration.Version, step.migration.Name, direction)
+	}
+
+	for _, statement := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("Error %w when running migration %d_%s %s - query: %s",
+				err, step.migration.Version, step.migration.Name, direction, statement)
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, end, step.migration.Version); err != nil {
+		return fmt.Errorf("Error %w when recording migration %d_%s %s", err, step.migration.Version, step.migration.Name, direction)
+	}
+
+	return nil
+}
+
+// applied returns the applied migrations by version, none when the migrations table does not exist yet.
+func (migrator *Migrator) applied(ctx context.Context, database Executor) (map[int64]appliedMigration, error) {
+	rows := make([]appliedMigration, 0)
+
+	err := NewQueryBuilder(database).
+		Select("`version`, `name`, `checksum`, `applied_at`, `dirty`").
+		From(migrator.table, "").
+		OrderBy("version", "ASC").
+		QueryIntoContext(ctx, &rows)
+
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchTable {
+		err = nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error %w when reading the migrations table %s", err, migrator.table)
+	}
+
+	applied := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+// lock takes the migration lock of the current database on the connection, waiting up to the lock timeout.
+func (migrator *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	var locked sql.NullInt64
+
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK("+migrationLockName+", ?)",
+		migrator.table, int(migrator.lockTimeout/time.Second)).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("Error %w when taking the migration lock", err)
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return ErrMigrationLocked
+	}
+
+	return nil
+}
+
+// unlock releases the migration lock, even when the context of the migration is done.
+func (migrator *Migrator) unlock(conn *sql.Conn) {
+	var released sql.NullInt64
+
+	_ = conn.QueryRowContext(context.Background(), "SELECT RELEASE_LOCK("+migrationLockName+")", migrator.table).Scan(&released)
+}
+
+// getSQLForCreateTable returns the statement creating the migrations table when it does not exist.
+func (migrator *Migrator) getSQLForCreateTable() string {
+	return "CREATE TABLE IF NOT EXISTS " + QuoteIdentifier(migrator.table) + " (" +
+		"`version` BIGINT NOT NULL, " +
+		"`name` VARCHAR(255) NOT NULL, " +
+		"`checksum` CHAR(64) NOT NULL, " +
+		"`applied_at` DATETIME NOT NULL, " +
+		"`dirty` TINYINT(1) NOT NULL DEFAULT 0, " +
+		"PRIMARY KEY (`version`))"
+}
+
+// find returns the migration of the given version.
+func (migrator *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range migrator.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+// appliedVersions returns the versions of the applied migrations in ascending order.
+func appliedVersions(applied map[int64]appliedMigration) []int64 {
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] < versions[j]
+	})
+
+	return versions
+}
+
+// splitStatements returns the statements of the SQL script separated by semicolons, ignoring the ones inside quoted
+// strings, quoted identifiers and comments. DELIMITER commands are not supported.
+func splitStatements(script string) []string {
+	statements := make([]string, 0)
+	start := 0
+
+	add := func(statement string) {
+		if statement = strings.TrimSpace(statement); statement != "" && !isCommentOnly(statement) {
+			statements = append(statements, statement)
+		}
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(script, i)
+		case c == '-' && strings.HasPrefix(script[i:], "-- "), c == '#':
+			i = skipUntil(script, i, "\n")
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			i = skipUntil(script, i+2, "*/") + 1
+		case c == ';':
+			add(script[start:i])
+			start = i + 1
+		}
+	}
+	add(script[start:])
+
+	return statements
+}
+
+// isCommentOnly returns whether the trimmed statement only holds comments.
+func isCommentOnly(statement string) bool {
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		case c == '-' && strings.HasPrefix(statement[i:], "-- "), c == '#':
+			i = skipUntil(statement, i, "\n")
+		case c == '/' && strings.HasPrefix(statement[i:], "/*") && !strings.HasPrefix(statement[i:], "/*!"):
+			i = skipUntil(statement, i+2, "*/") + 1
+		default:
+			return false
+		}
+	}
+
+	return true
+}
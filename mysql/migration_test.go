@@ -0,0 +1,252 @@
+package mysql_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/atrapalo/go-base/mysql"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const (
+	createHotelsUp   = "CREATE TABLE hotel (id INT NOT NULL, PRIMARY KEY (id));"
+	createHotelsDown = "DROP TABLE hotel;"
+	addNameUp        = "-- adds the name\nALTER TABLE hotel ADD name VARCHAR(255) NOT NULL DEFAULT 'a;b';\nCREATE INDEX idx_name ON hotel (name);\n"
+	addNameDown      = "ALTER TABLE hotel DROP name;"
+)
+
+var (
+	lockSql    = regexp.QuoteMeta("SELECT GET_LOCK(SHA2(CONCAT(DATABASE(), '.', ?), 256), ?)")
+	unlockSql  = regexp.QuoteMeta("SELECT RELEASE_LOCK(SHA2(CONCAT(DATABASE(), '.', ?), 256))")
+	createSql  = regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `schema_migrations`")
+	appliedSql = regexp.QuoteMeta("SELECT `version`, `name`, `checksum`, `applied_at`, `dirty` FROM `schema_migrations` ORDER BY `version` ASC")
+	insertSql  = regexp.QuoteMeta("INSERT INTO `schema_migrations` (`version`, `name`, `checksum`, `applied_at`, `dirty`) VALUES (?, ?, ?, NOW(), 1)")
+	cleanSql   = regexp.QuoteMeta("UPDATE `schema_migrations` SET `applied_at` = NOW(), `dirty` = 0 WHERE `version` = ?")
+	dirtySql   = regexp.QuoteMeta("UPDATE `schema_migrations` SET `dirty` = 1 WHERE `version` = ?")
+	deleteSql  = regexp.QuoteMeta("DELETE FROM `schema_migrations` WHERE `version` = ?")
+)
+
+func migrationFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/1_create_hotels.up.sql":   {Data: []byte(createHotelsUp)},
+		"migrations/1_create_hotels.down.sql": {Data: []byte(createHotelsDown)},
+		"migrations/2_add_name.up.sql":        {Data: []byte(addNameUp)},
+		"migrations/2_add_name.down.sql":      {Data: []byte(addNameDown)},
+		"migrations/README.md":                {Data: []byte("migrations")},
+	}
+}
+
+func checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func appliedRows(versions ...int64) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at", "dirty"})
+	scripts := map[int64][]interface{}{
+		1: {"create_hotels", checksum(createHotelsUp)},
+		2: {"add_name", checksum(addNameUp)},
+	}
+
+	for _, version := range versions {
+		rows.AddRow(version, scripts[version][0], scripts[version][1], []byte("2024-01-31 12:00:00"), 0)
+	}
+
+	return rows
+}
+
+func Test_load_migrations(t *testing.T) {
+	migrations, err := mysql.LoadMigrations(migrationFiles(), "migrations")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []mysql.Migration{
+		{Version: 1, Name: "create_hotels", Up: createHotelsUp, Down: createHotelsDown, Checksum: checksum(createHotelsUp)},
+		{Version: 2, Name: "add_name", Up: addNameUp, Down: addNameDown, Checksum: checksum(addNameUp)},
+	}, migrations)
+
+	files := migrationFiles()
+	delete(files, "migrations/2_add_name.up.sql")
+	_, err = mysql.LoadMigrations(files, "migrations")
+	assert.EqualError(t, err, "migration 2_add_name has no up file")
+
+	files["migrations/add_name.sql"] = &fstest.MapFile{Data: []byte(addNameUp)}
+	_, err = mysql.LoadMigrations(files, "migrations")
+	assert.EqualError(t, err, "migration file add_name.sql is not named like VERSION_NAME.up.sql or VERSION_NAME.down.sql")
+}
+
+func Test_migrator_up(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(lockSql).WithArgs("schema_migrations", 5).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec(createSql).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(appliedSql).WillReturnRows(appliedRows(1))
+	mock.ExpectExec(insertSql).WithArgs(2, "add_name", checksum(addNameUp)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("-- adds the name\nALTER TABLE hotel ADD name VARCHAR(255) NOT NULL DEFAULT 'a;b'")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_name ON hotel (name)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(cleanSql).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(unlockSql).WithArgs("schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+
+	migrator, err2 := mysql.NewConnectionFromDB(db).NewMigrator(migrationFiles(), "migrations")
+	assert.Nil(t, err2)
+
+	migrations, err3 := migrator.SetLockTimeout(5 * time.Second).Up()
+	assert.Nil(t, err3)
+	assert.Len(t, migrations, 1)
+	assert.Equal(t, int64(2), migrations[0].Version)
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+}
+
+func Test_migrator_to_version_reverts_migrations(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(lockSql).WithArgs("schema_migrations", 60).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec(createSql).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(appliedSql).WillReturnRows(appliedRows(1, 2))
+	mock.ExpectExec(dirtySql).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE hotel DROP name")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(deleteSql).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(dirtySql).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE hotel")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(deleteSql).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(unlockSql).WithArgs("schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+
+	migrator, err2 := mysql.NewConnectionFromDB(db).NewMigrator(migrationFiles(), "migrations")
+	assert.Nil(t, err2)
+
+	migrations, err3 := migrator.To(0)
+	assert.Nil(t, err3)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, "add_name", migrations[0].Name)
+	assert.Equal(t, "create_hotels", migrations[1].Name)
+
+	_, err4 := migrator.To(3)
+	assert.EqualError(t, err4, "migration version 3 does not exist")
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
+
+func Test_migrator_dry_run_down(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(appliedSql).WillReturnRows(appliedRows(1, 2))
+
+	migrator, err2 := mysql.NewConnectionFromDB(db).NewMigrator(migrationFiles(), "migrations")
+	assert.Nil(t, err2)
+
+	migrations, err3 := migrator.SetDryRun(true).Down()
+	assert.Nil(t, err3)
+	assert.Len(t, migrations, 1)
+	assert.Equal(t, addNameDown, migrations[0].Down)
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+}
+
+func Test_migrator_locked(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(lockSql).WithArgs("schema_migrations", 60).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+
+	migrator, err2 := mysql.NewConnectionFromDB(db).NewMigrator(migrationFiles(), "migrations")
+	assert.Nil(t, err2)
+
+	_, err3 := migrator.Up()
+	assert.True(t, errors.Is(err3, mysql.ErrMigrationLocked))
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+}
+
+func Test_migrator_records_failed_migrations_as_dirty(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(lockSql).WithArgs("schema_migrations", 60).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec(createSql).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(appliedSql).WillReturnRows(appliedRows(1))
+	mock.ExpectExec(insertSql).WithArgs(2, "add_name", checksum(addNameUp)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("-- adds the name\nALTER TABLE hotel ADD name VARCHAR(255) NOT NULL DEFAULT 'a;b'")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_name ON hotel (name)")).WillReturnError(errors.New("duplicate key name"))
+	mock.ExpectQuery(unlockSql).WithArgs("schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectQuery(appliedSql).WillReturnRows(appliedRows(1).AddRow(2, "add_name", checksum(addNameUp), []byte("2024-02-01 08:30:00"), 1))
+
+	migrator, err2 := mysql.NewConnectionFromDB(db).NewMigrator(migrationFiles(), "migrations")
+	assert.Nil(t, err2)
+
+	migrations, err3 := migrator.Up()
+	assert.EqualError(t, err3, "Error duplicate key name when running migration 2_add_name up - query: CREATE INDEX idx_name ON hotel (name)")
+	assert.Empty(t, migrations)
+
+	_, err4 := migrator.SetDryRun(true).Up()
+	assert.True(t, errors.Is(err4, mysql.ErrMigrationDirty))
+	assert.EqualError(t, err4, "schema was left dirty by migration 2_add_name, repair the schema and its row in the migrations table schema_migrations")
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
+
+func Test_migrator_rejects_modified_migrations(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(appliedSql).WillReturnRows(appliedRows(1))
+
+	files := migrationFiles()
+	files["migrations/1_create_hotels.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE hotel (id BIGINT);")}
+
+	migrator, err2 := mysql.NewConnectionFromDB(db).NewMigrator(files, "migrations")
+	assert.Nil(t, err2)
+
+	_, err3 := migrator.SetDryRun(true).Up()
+	assert.EqualError(t, err3, "migration 1_create_hotels was modified after being applied")
+}
+
+func Test_migrator_status(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(appliedSql).WillReturnError(&mysqldriver.MySQLError{Number: 1146, Message: "Table 'schema_migrations' doesn't exist"})
+	mock.ExpectQuery(appliedSql).WillReturnRows(appliedRows(1).AddRow(3, "add_stars", "abc", []byte("2024-02-01 08:30:00"), 1))
+
+	migrator, err2 := mysql.NewConnectionFromDB(db).NewMigrator(migrationFiles(), "migrations")
+	assert.Nil(t, err2)
+
+	statuses, err3 := migrator.Status()
+	assert.Nil(t, err3)
+	assert.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+
+	statuses, err4 := migrator.Status()
+	assert.Nil(t, err4)
+	assert.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Applied)
+	assert.Equal(t, time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), statuses[0].AppliedAt)
+	assert.False(t, statuses[1].Applied)
+	assert.Equal(t, mysql.MigrationStatus{
+		Migration: mysql.Migration{Version: 3, Name: "add_stars", Checksum: "abc"},
+		Applied:   true,
+		AppliedAt: time.Date(2024, 2, 1, 8, 30, 0, 0, time.UTC),
+		Missing:   true,
+		Dirty:     true,
+	}, statuses[2])
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
@@ -0,0 +1,242 @@
+package mysql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// boundPlaceholder replaces the ? placeholders whose param is bound by the query part rendering them, so that the
+// params set with SetParam only fill the remaining ? placeholders, whatever the order of the parts.
+const boundPlaceholder = "\x00"
+
+// bindParams rewrites every :name placeholder into a positional one, expands every placeholder bound to a
+// QueryBuilder into its parenthesized subquery, rendered with the given dialect, and returns the params in
+// placeholder order. The params of the query parts fill their bound placeholders and the params set with SetParam
+// fill the remaining ? placeholders, any extra ones are bound last.
+func bindParams(sqlString string, params []interface{}, legacy []interface{}, named map[string]interface{}, dialect Dialect) (string, []interface{}, error) {
+	if !strings.ContainsAny(sqlString, ":"+boundPlaceholder) && !hasSubQuery(legacy) {
+		return sqlString, append(append([]interface{}{}, params...), legacy...), nil
+	}
+
+	var builder strings.Builder
+	bound := make([]interface{}, 0, len(params)+len(legacy))
+	next, nextLegacy := 0, 0
+
+	err := walkPlaceholders(sqlString, func(chunk string, placeholder string) error {
+		builder.WriteString(chunk)
+
+		var value interface{}
+		switch {
+		case placeholder == "":
+			return nil
+		case placeholder == boundPlaceholder:
+			if next >= len(params) {
+				builder.WriteString("?")
+				return nil
+			}
+			value = params[next]
+			next++
+		case placeholder == "?":
+			if nextLegacy >= len(legacy) {
+				builder.WriteString("?")
+				return nil
+			}
+			value = legacy[nextLegacy]
+			nextLegacy++
+		default:
+			name := placeholder[1:]
+			v, ok := named[name]
+			if !ok {
+				return fmt.Errorf("missing value for named param :%s", name)
+			}
+			value = v
+		}
+
+		subQuery, isSubQuery := value.(*QueryBuilder)
+		if !isSubQuery {
+			builder.WriteString("?")
+			bound = append(bound, value)
+			return nil
+		}
+
+		subSql, subParams, err := subQuery.withDialect(dialect).buildPositional()
+		if err != nil {
+			return err
+		}
+		builder.WriteString("(" + subSql + ")")
+		bound = append(bound, subParams...)
+
+		return nil
+	})
+	if err != nil {
+		return sqlString, append(append([]interface{}{}, params...), legacy...), err
+	}
+
+	return builder.String(), append(append(bound, params[next:]...), legacy[nextLegacy:]...), nil
+}
+
+// bindPlaceholders returns the SQL string with its first count ? placeholders marked as bound.
+func bindPlaceholders(sqlString string, count int) string {
+	if count == 0 || !strings.Contains(sqlString, "?") {
+		return sqlString
+	}
+
+	var builder strings.Builder
+
+	_ = walkPlaceholders(sqlString, func(chunk string, placeholder string) error {
+		builder.WriteString(chunk)
+
+		if placeholder == "?" && count > 0 {
+			builder.WriteString(boundPlaceholder)
+			count--
+			return nil
+		}
+		builder.WriteString(placeholder)
+
+		return nil
+	})
+
+	return builder.String()
+}
+
+// countPlaceholders returns the number of ? placeholders of the SQL string that have no bound param.
+func countPlaceholders(sqlString string) int {
+	count := 0
+	_ = walkPlaceholders(sqlString, func(chunk string, placeholder string) error {
+		if placeholder == "?" {
+			count++
+		}
+
+		return nil
+	})
+
+	return count
+}
+
+// checkNamedParams returns an error when a named param has no :name placeholder in the SQL string.
+func checkNamedParams(sqlString string, named map[string]interface{}) error {
+	if len(named) == 0 {
+		return nil
+	}
+
+	used := map[string]bool{}
+	_ = walkPlaceholders(sqlString, func(chunk string, placeholder string) error {
+		if strings.HasPrefix(placeholder, ":") {
+			used[placeholder[1:]] = true
+		}
+
+		return nil
+	})
+
+	names := make([]string, 0, len(named))
+	for name := range named {
+		if !used[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		return fmt.Errorf("unused value for named param :%s", names[0])
+	}
+
+	return nil
+}
+
+// hasSubQuery returns whether any of the params is a QueryBuilder.
+func hasSubQuery(params []interface{}) bool {
+	for _, param := range params {
+		if _, ok := param.(*QueryBuilder); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// walkPlaceholders calls fn with every chunk of SQL text followed by the placeholder that ends it, either "?", a
+// bound placeholder or ":name", ignoring the ones inside quoted strings, quoted identifiers and comments. The last chunk has no placeholder.
+func walkPlaceholders(sqlString string, fn func(chunk string, placeholder string) error) error {
+	start := 0
+
+	for i := 0; i < len(sqlString); i++ {
+		c := sqlString[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sqlString, i)
+		case c == '-' && strings.HasPrefix(sqlString[i:], "-- "), c == '#':
+			i = skipUntil(sqlString, i, "\n")
+		case c == '/' && strings.HasPrefix(sqlString[i:], "/*"):
+			i = skipUntil(sqlString, i+2, "*/") + 1
+		case c == '?', c == boundPlaceholder[0]:
+			if err := fn(sqlString[start:i], sqlString[i:i+1]); err != nil {
+				return err
+			}
+			start = i + 1
+		case c == ':' && isNamedPlaceholderAt(sqlString, i):
+			end := i + 1
+			for end < len(sqlString) && isIdentifierChar(sqlString[end]) {
+				end++
+			}
+			if err := fn(sqlString[start:i], sqlString[i:end]); err != nil {
+				return err
+			}
+			start = end
+			i = end - 1
+		}
+	}
+
+	return fn(sqlString[start:], "")
+}
+
+// isNamedPlaceholderAt returns whether a :name placeholder starts at position i.
+func isNamedPlaceholderAt(sqlString string, i int) bool {
+	if i+1 >= len(sqlString) || !isIdentifierStart(sqlString[i+1]) {
+		return false
+	}
+
+	return i == 0 || (sqlString[i-1] != ':' && !isIdentifierChar(sqlString[i-1]))
+}
+
+// skipQuoted returns the position of the quote closing the one at position i.
+func skipQuoted(sqlString string, i int) int {
+	quote := sqlString[i]
+
+	for j := i + 1; j < len(sqlString); j++ {
+		switch sqlString[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			if j+1 < len(sqlString) && sqlString[j+1] == quote {
+				j++
+				continue
+			}
+
+			return j
+		}
+	}
+
+	return len(sqlString)
+}
+
+// skipUntil returns the position where the terminator following position i starts.
+func skipUntil(sqlString string, i int, terminator string) int {
+	end := strings.Index(sqlString[i:], terminator)
+	if end == -1 {
+		return len(sqlString)
+	}
+
+	return i + end
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentifierChar(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9')
+}
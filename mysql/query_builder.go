@@ -1,7 +1,9 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -23,22 +25,35 @@ const (
 	Inner     = "INNER"
 	Left      = "LEFT"
 	Right     = "RIGHT"
+	Union     = "UNION"
+	UnionAll  = "UNION ALL"
 )
 
 type (
-	// FromSqlParts records table and alias
+	// FromSqlParts records table and alias, or the subQuery used as derived table
 	FromSqlParts struct {
 		table, alias string
+		subQuery     *QueryBuilder
 	}
 
-	// OrderBySqlParts records sort and order
+	// OrderBySqlParts records sort and order, raw sorts are rendered as given
 	OrderBySqlParts struct {
 		sort, order string
+		raw         bool
 	}
 
-	// JoinSqlParts records joinType, joinTable, joinAlias, joinCondition
+	// GroupBySqlParts records column, raw columns are rendered as given
+	GroupBySqlParts struct {
+		column string
+		raw    bool
+	}
+
+	// JoinSqlParts records joinType, joinTable, joinAlias, joinCondition, the joinUsing columns
+	// and the joinSubQuery used as derived table
 	JoinSqlParts struct {
 		joinType, joinTable, joinAlias, joinCondition string
+		joinUsing                                     []string
+		joinSubQuery                                  *QueryBuilder
 	}
 
 	// ValuesSqlParts records key, val
@@ -53,62 +68,169 @@ type (
 		val interface{}
 	}
 
+	// OnDuplicateSqlParts records key and the expression it is set to, or its inserted value when there is none
+	OnDuplicateSqlParts struct {
+		key        string
+		expression *Expression
+	}
+
+	// WithSqlParts records the name of a common table expression, the query it names and whether it is recursive
+	WithSqlParts struct {
+		name      string
+		query     *QueryBuilder
+		recursive bool
+	}
+
+	// IndexHintSqlParts records the alias, or name, of the table hinted, the hintType and the indexes
+	IndexHintSqlParts struct {
+		table, hintType string
+		indexes         []string
+	}
+
+	// UnionSqlParts records unionType and the query combined
+	UnionSqlParts struct {
+		unionType string
+		query     *QueryBuilder
+	}
+
+	// statement records a rendered sql string and its params
+	statement struct {
+		sql    string
+		params []interface{}
+	}
+
 	//QueryBuilder defined a SQL query builder.
 	QueryBuilder struct {
-		firstResult, maxResults, queryType                                                 int
-		flag, hasSort, sql, sqlPartsSelect, sqlPartsWhere, sqlPartsGroupBy, sqlPartsHaving string
-		database                                                                           *sql.DB
-		State                                                                              *sql.Stmt
-		params                                                                             []interface{}
-		sqlPartsFrom                                                                       []FromSqlParts
-		sqlPartsOrderBy                                                                    []OrderBySqlParts
-		sqlPartsValues                                                                     []ValuesSqlParts
-		sqlPartsSet                                                                        []SetSqlParts
-		sqlPartsJoin                                                                       []JoinSqlParts
+		firstResult, maxResults, queryType int
+		maxStatementSize                   int
+		debugValueLength                   int
+		planRowsThreshold                  int64
+		planWarningHook                    PlanWarningHook
+		flag, hasSort                      string
+		sqlPartsGroupBy                    []GroupBySqlParts
+		sqlPartsSelect                     []Expression
+		selectModifiers, optimizerHints    []string
+		insertVerb                         string
+		insertColumns                      []string
+		deleteTargets                      []string
+		conflictColumns                    []string
+		lockMode, lockOption               string
+		sqlPartsWhere, sqlPartsHaving      Expression
+		database                           Executor
+		dialect                            Dialect
+		statements                         *statementCache
+		scopes                             []Scope
+		params                             []interface{}
+		namedParams                        map[string]interface{}
+		sqlPartsFrom                       []FromSqlParts
+		sqlPartsOrderBy                    []OrderBySqlParts
+		sqlPartsValues                     []ValuesSqlParts
+		sqlPartsSet                        []SetSqlParts
+		sqlPartsJoin                       []JoinSqlParts
+		sqlPartsRows                       [][]interface{}
+		sqlPartsOnDuplicate                []OnDuplicateSqlParts
+		sqlPartsUnion                      []UnionSqlParts
+		sqlPartsWith                       []WithSqlParts
+		sqlPartsIndexHint                  []IndexHintSqlParts
+		err                                error
+		allowedIdentifiers                 map[string]bool
+		withoutScopes                      map[string]bool
+		sqlPartsAfter                      []interface{}
+
+		// Deprecated: State is no longer set, executed statements are either closed or owned by the statement cache.
+		State *sql.Stmt
 	}
 )
 
-// NewQueryBuilder returns a newly initialized QueryBuilder that implements QueryBuilder
-func NewQueryBuilder(database *sql.DB) *QueryBuilder {
+// NewQueryBuilder returns a newly initialized QueryBuilder that implements QueryBuilder,
+// the database may be a *sql.DB, a *sql.Tx or a *sql.Conn.
+func NewQueryBuilder(database Executor) *QueryBuilder {
 	return &QueryBuilder{
-		firstResult:     0,
-		maxResults:      -1,
-		queryType:       Select,
-		database:        database,
-		params:          []interface{}{},
-		flag:            IsDefault,
-		sql:             "",
-		sqlPartsSet:     make([]SetSqlParts, 0),
-		sqlPartsValues:  make([]ValuesSqlParts, 0),
-		sqlPartsFrom:    make([]FromSqlParts, 0),
-		sqlPartsOrderBy: make([]OrderBySqlParts, 0),
-		sqlPartsJoin:    make([]JoinSqlParts, 0),
+		firstResult:      0,
+		maxResults:       -1,
+		queryType:        Select,
+		maxStatementSize: DefaultMaxStatementSize,
+		debugValueLength: DefaultDebugValueLength,
+		insertVerb:       InsertInto,
+		database:         database,
+		dialect:          MySQLDialect{},
+		params:           []interface{}{},
+		namedParams:      map[string]interface{}{},
+		flag:             IsDefault,
+		sqlPartsSet:      make([]SetSqlParts, 0),
+		sqlPartsValues:   make([]ValuesSqlParts, 0),
+		sqlPartsFrom:     make([]FromSqlParts, 0),
+		sqlPartsOrderBy:  make([]OrderBySqlParts, 0),
+		sqlPartsJoin:     make([]JoinSqlParts, 0),
 	}
 }
 
 // GetParams returns queryBuilder params
 func (queryBuilder *QueryBuilder) GetParams() []interface{} {
-	return queryBuilder.params
+	return queryBuilder.GetParameters()
 }
 
 // Select returns QueryBuilder that Specifies an item that has to be returned to the query result.
 func (queryBuilder *QueryBuilder) Select(value string) *QueryBuilder {
 	queryBuilder.queryType = Select
-	queryBuilder.sqlPartsSelect = value
+	queryBuilder.sqlPartsSelect = []Expression{Expr(value)}
 
 	return queryBuilder
 }
 
+// AddSelect returns QueryBuilder that adds an item to the query result, binding the given params to its placeholders.
+func (queryBuilder *QueryBuilder) AddSelect(value string, params ...interface{}) *QueryBuilder {
+	queryBuilder.queryType = Select
+	queryBuilder.sqlPartsSelect = append(queryBuilder.sqlPartsSelect, Expr(value, params...))
+
+	return queryBuilder
+}
+
+// SelectSubQuery returns QueryBuilder that adds the scalar subquery, named after alias, to the query result.
+func (queryBuilder *QueryBuilder) SelectSubQuery(subQuery *QueryBuilder, alias string) *QueryBuilder {
+	return queryBuilder.AddSelect("? AS "+alias, subQuery)
+}
+
 // From returns QueryBuilder that creates and adds a query root corresponding to the table identified by the
 // given alias, forming a cartesian product with any existing query roots.
+// The table may also be a derived table written as a parenthesized SELECT statement.
 func (queryBuilder *QueryBuilder) From(table string, alias string) *QueryBuilder {
 	queryBuilder.setFromWrap(table, alias)
 
 	return queryBuilder
 }
 
+// FromSubQuery returns QueryBuilder that adds the subquery as a derived table query root identified by the given alias.
+func (queryBuilder *QueryBuilder) FromSubQuery(subQuery *QueryBuilder, alias string) *QueryBuilder {
+	queryBuilder.sqlPartsFrom = append(queryBuilder.sqlPartsFrom, FromSqlParts{alias: alias, subQuery: subQuery})
+
+	return queryBuilder
+}
+
+// Union returns QueryBuilder that combines the distinct results of the given select queries with the query results,
+// ORDER BY and LIMIT apply to the combined results and the query is only combined when it has a query root.
+func (queryBuilder *QueryBuilder) Union(queries ...*QueryBuilder) *QueryBuilder {
+	return queryBuilder.addUnion(Union, queries)
+}
+
+// UnionAll returns QueryBuilder that combines every result of the given select queries with the query results,
+// ORDER BY and LIMIT apply to the combined results and the query is only combined when it has a query root.
+func (queryBuilder *QueryBuilder) UnionAll(queries ...*QueryBuilder) *QueryBuilder {
+	return queryBuilder.addUnion(UnionAll, queries)
+}
+
+// addUnion appends the queries to the union parts.
+func (queryBuilder *QueryBuilder) addUnion(unionType string, queries []*QueryBuilder) *QueryBuilder {
+	queryBuilder.queryType = Select
+	for _, query := range queries {
+		queryBuilder.sqlPartsUnion = append(queryBuilder.sqlPartsUnion, UnionSqlParts{unionType: unionType, query: query})
+	}
+
+	return queryBuilder
+}
+
 // Update returns QueryBuilder that turns the query being built into a bulk update query that ranges over
-//a certain table
+// a certain table
 func (queryBuilder *QueryBuilder) Update(table string, alias string) *QueryBuilder {
 	queryBuilder.queryType = Update
 	queryBuilder.setFromWrap(table, alias)
@@ -130,33 +252,130 @@ func (queryBuilder *QueryBuilder) Value(key string, val interface{}) *QueryBuild
 	return queryBuilder
 }
 
+// AllowIdentifiers returns QueryBuilder that restricts the columns accepted by the following OrderBy and GroupBy
+// calls to the given ones, any other column is rejected with an InvalidIdentifierError.
+func (queryBuilder *QueryBuilder) AllowIdentifiers(identifiers ...string) *QueryBuilder {
+	if queryBuilder.allowedIdentifiers == nil {
+		queryBuilder.allowedIdentifiers = map[string]bool{}
+	}
+
+	for _, identifier := range identifiers {
+		queryBuilder.allowedIdentifiers[unquoteIdentifier(identifier)] = true
+	}
+
+	return queryBuilder
+}
+
 // OrderBy returns QueryBuilder that specifies an ordering for the query results.
+// The sort must be a plain or qualified column name, allowed by AllowIdentifiers when set, and the order ASC or DESC,
+// otherwise the ordering is ignored and the query fails with an InvalidIdentifierError.
 func (queryBuilder *QueryBuilder) OrderBy(sort string, order string) *QueryBuilder {
+	order, err := normalizeOrder(order)
+	if err == nil {
+		err = queryBuilder.validateIdentifier(sort)
+	}
+	if err != nil {
+		queryBuilder.setError(err)
+		return queryBuilder
+	}
 
 	queryBuilder.hasSort = HasSort
-	if order == "" {
-		order = "ASC"
+	queryBuilder.sqlPartsOrderBy = append(queryBuilder.sqlPartsOrderBy, OrderBySqlParts{sort: sort, order: order})
+
+	return queryBuilder
+}
+
+// OrderByRaw returns QueryBuilder that specifies an ordering for the query results by a trusted expression
+// rendered as given, the order must still be ASC or DESC.
+func (queryBuilder *QueryBuilder) OrderByRaw(expression string, order string) *QueryBuilder {
+	order, err := normalizeOrder(order)
+	if err != nil {
+		queryBuilder.setError(err)
+		return queryBuilder
 	}
 
-	queryBuilder.sqlPartsOrderBy = append(queryBuilder.sqlPartsOrderBy, OrderBySqlParts{sort, order})
+	queryBuilder.hasSort = HasSort
+	queryBuilder.sqlPartsOrderBy = append(queryBuilder.sqlPartsOrderBy, OrderBySqlParts{sort: expression, order: order, raw: true})
 
 	return queryBuilder
 }
 
 // GroupBy returns QueryBuilder that specifies a grouping over the results of the query.
+// It takes a comma separated list of plain or qualified column names, allowed by AllowIdentifiers when set,
+// otherwise the grouping is ignored and the query fails with an InvalidIdentifierError.
 func (queryBuilder *QueryBuilder) GroupBy(groupBy string) *QueryBuilder {
 	if groupBy == "" {
 		return queryBuilder
 	}
 
-	queryBuilder.sqlPartsGroupBy = groupBy
+	columns := make([]GroupBySqlParts, 0)
+	for _, column := range strings.Split(groupBy, ",") {
+		column = strings.TrimSpace(column)
+		if err := queryBuilder.validateIdentifier(column); err != nil {
+			queryBuilder.setError(err)
+			return queryBuilder
+		}
+		columns = append(columns, GroupBySqlParts{column: column})
+	}
+
+	queryBuilder.sqlPartsGroupBy = columns
 
 	return queryBuilder
 }
 
-// Having returns QueryBuilder that specifies a restriction over the groups of the query.
-func (queryBuilder *QueryBuilder) Having(having string) *QueryBuilder {
-	queryBuilder.sqlPartsHaving = having
+// GroupByRaw returns QueryBuilder that specifies a grouping over the results of the query by a trusted expression
+// rendered as given.
+func (queryBuilder *QueryBuilder) GroupByRaw(expression string) *QueryBuilder {
+	if expression == "" {
+		return queryBuilder
+	}
+
+	queryBuilder.sqlPartsGroupBy = []GroupBySqlParts{{column: expression, raw: true}}
+
+	return queryBuilder
+}
+
+// Err returns the first error found while building the query, such as an InvalidIdentifierError,
+// the query fails with it when executed.
+func (queryBuilder *QueryBuilder) Err() error {
+	return queryBuilder.err
+}
+
+// setError records the first error found while building the query.
+func (queryBuilder *QueryBuilder) setError(err error) {
+	if queryBuilder.err == nil {
+		queryBuilder.err = err
+	}
+}
+
+// validateIdentifier returns an InvalidIdentifierError when the identifier is not a plain or qualified column name
+// or it is not allowed in the query.
+func (queryBuilder *QueryBuilder) validateIdentifier(identifier string) error {
+	if err := ValidateIdentifier(identifier); err != nil {
+		return err
+	}
+
+	if queryBuilder.allowedIdentifiers != nil && !queryBuilder.allowedIdentifiers[unquoteIdentifier(identifier)] {
+		return &InvalidIdentifierError{Identifier: identifier, Reason: "not allowed in this query"}
+	}
+
+	return nil
+}
+
+// quote returns the identifier quoted when it is a plain or qualified identifier, or unchanged otherwise.
+func (queryBuilder *QueryBuilder) quote(identifier string) string {
+	return queryBuilder.dialect.QuoteIdentifier(identifier)
+}
+
+// quoteTable returns the quoted table followed by its quoted alias, if any.
+func (queryBuilder *QueryBuilder) quoteTable(table string, alias string) string {
+	return strings.TrimSpace(queryBuilder.quote(table) + " " + queryBuilder.quote(alias))
+}
+
+// Having returns QueryBuilder that specifies a restriction over the groups of the query,
+// binding the given params to its placeholders.
+func (queryBuilder *QueryBuilder) Having(having string, params ...interface{}) *QueryBuilder {
+	queryBuilder.sqlPartsHaving = Expr(having, params...)
 
 	return queryBuilder
 }
@@ -168,9 +387,41 @@ func (queryBuilder *QueryBuilder) SetFirstResult(firstResult int) *QueryBuilder
 	return queryBuilder
 }
 
-// Where returns QueryBuilder that specifies one or more restrictions to the query result.
-func (queryBuilder *QueryBuilder) Where(condition string) *QueryBuilder {
-	queryBuilder.sqlPartsWhere = condition
+// Where returns QueryBuilder that specifies one or more restrictions to the query result,
+// replacing any previous restriction and binding the given params to its placeholders.
+func (queryBuilder *QueryBuilder) Where(condition string, params ...interface{}) *QueryBuilder {
+	return queryBuilder.WhereExpr(Expr(condition, params...))
+}
+
+// AndWhere returns QueryBuilder that adds a restriction to the query result joined with AND,
+// binding the given params to its placeholders.
+func (queryBuilder *QueryBuilder) AndWhere(condition string, params ...interface{}) *QueryBuilder {
+	return queryBuilder.AndWhereExpr(Expr(condition, params...))
+}
+
+// OrWhere returns QueryBuilder that adds a restriction to the query result joined with OR,
+// binding the given params to its placeholders.
+func (queryBuilder *QueryBuilder) OrWhere(condition string, params ...interface{}) *QueryBuilder {
+	return queryBuilder.OrWhereExpr(Expr(condition, params...))
+}
+
+// WhereExpr returns QueryBuilder that specifies the expression as the only restriction to the query result.
+func (queryBuilder *QueryBuilder) WhereExpr(expression Expression) *QueryBuilder {
+	queryBuilder.sqlPartsWhere = expression
+
+	return queryBuilder
+}
+
+// AndWhereExpr returns QueryBuilder that adds the expression to the query restrictions joined with AND.
+func (queryBuilder *QueryBuilder) AndWhereExpr(expression Expression) *QueryBuilder {
+	queryBuilder.sqlPartsWhere = And(queryBuilder.sqlPartsWhere, expression)
+
+	return queryBuilder
+}
+
+// OrWhereExpr returns QueryBuilder that adds the expression to the query restrictions joined with OR.
+func (queryBuilder *QueryBuilder) OrWhereExpr(expression Expression) *QueryBuilder {
+	queryBuilder.sqlPartsWhere = Or(queryBuilder.sqlPartsWhere, expression)
 
 	return queryBuilder
 }
@@ -181,25 +432,55 @@ func (queryBuilder *QueryBuilder) Join(join string, alias string, condition stri
 }
 
 // InnerJoin returns QueryBuilder that creates and adds a join to the query.
+// The joined table may also be a derived table written as a parenthesized SELECT statement.
 func (queryBuilder *QueryBuilder) InnerJoin(join string, alias string, condition string) *QueryBuilder {
-	queryBuilder.flag = IsJoin
-	queryBuilder.sqlPartsJoin = append(queryBuilder.sqlPartsJoin, JoinSqlParts{joinType: Inner, joinTable: join, joinAlias: alias, joinCondition: condition})
-
-	return queryBuilder
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Inner, joinTable: join, joinAlias: alias, joinCondition: condition})
 }
 
 // LeftJoin returns QueryBuilder that creates and adds a left join to the query.
 func (queryBuilder *QueryBuilder) LeftJoin(join string, alias string, condition string) *QueryBuilder {
-	queryBuilder.flag = IsJoin
-	queryBuilder.sqlPartsJoin = append(queryBuilder.sqlPartsJoin, JoinSqlParts{joinType: Left, joinTable: join, joinAlias: alias, joinCondition: condition})
-
-	return queryBuilder
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Left, joinTable: join, joinAlias: alias, joinCondition: condition})
 }
 
 // RightJoin returns QueryBuilder that creates and adds a right join to the query.
 func (queryBuilder *QueryBuilder) RightJoin(join string, alias string, condition string) *QueryBuilder {
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Right, joinTable: join, joinAlias: alias, joinCondition: condition})
+}
+
+// InnerJoinUsing returns QueryBuilder that creates and adds a join matching the given columns with USING (...).
+func (queryBuilder *QueryBuilder) InnerJoinUsing(join string, alias string, columns ...string) *QueryBuilder {
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Inner, joinTable: join, joinAlias: alias, joinUsing: columns})
+}
+
+// LeftJoinUsing returns QueryBuilder that creates and adds a left join matching the given columns with USING (...).
+func (queryBuilder *QueryBuilder) LeftJoinUsing(join string, alias string, columns ...string) *QueryBuilder {
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Left, joinTable: join, joinAlias: alias, joinUsing: columns})
+}
+
+// RightJoinUsing returns QueryBuilder that creates and adds a right join matching the given columns with USING (...).
+func (queryBuilder *QueryBuilder) RightJoinUsing(join string, alias string, columns ...string) *QueryBuilder {
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Right, joinTable: join, joinAlias: alias, joinUsing: columns})
+}
+
+// InnerJoinSubQuery returns QueryBuilder that creates and adds a join against the subquery as a derived table.
+func (queryBuilder *QueryBuilder) InnerJoinSubQuery(subQuery *QueryBuilder, alias string, condition string) *QueryBuilder {
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Inner, joinSubQuery: subQuery, joinAlias: alias, joinCondition: condition})
+}
+
+// LeftJoinSubQuery returns QueryBuilder that creates and adds a left join against the subquery as a derived table.
+func (queryBuilder *QueryBuilder) LeftJoinSubQuery(subQuery *QueryBuilder, alias string, condition string) *QueryBuilder {
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Left, joinSubQuery: subQuery, joinAlias: alias, joinCondition: condition})
+}
+
+// RightJoinSubQuery returns QueryBuilder that creates and adds a right join against the subquery as a derived table.
+func (queryBuilder *QueryBuilder) RightJoinSubQuery(subQuery *QueryBuilder, alias string, condition string) *QueryBuilder {
+	return queryBuilder.addJoin(JoinSqlParts{joinType: Right, joinSubQuery: subQuery, joinAlias: alias, joinCondition: condition})
+}
+
+// addJoin appends a join to the query, joins are rendered in the order they were added.
+func (queryBuilder *QueryBuilder) addJoin(join JoinSqlParts) *QueryBuilder {
 	queryBuilder.flag = IsJoin
-	queryBuilder.sqlPartsJoin = append(queryBuilder.sqlPartsJoin, JoinSqlParts{joinType: Right, joinTable: join, joinAlias: alias, joinCondition: condition})
+	queryBuilder.sqlPartsJoin = append(queryBuilder.sqlPartsJoin, join)
 
 	return queryBuilder
 }
@@ -222,205 +503,415 @@ func (queryBuilder *QueryBuilder) GetMaxResults() int {
 }
 
 // SetParam sets a query parameter for the query being constructed.
+// Params set this way are bound in order to the ? placeholders that have no param bound by the query parts.
 func (queryBuilder *QueryBuilder) SetParam(param interface{}) *QueryBuilder {
 	queryBuilder.params = append(queryBuilder.params, param)
 
 	return queryBuilder
 }
 
-// GetParameters gets all defined query parameters for the query being constructed indexed by parameter index or name.
+// SetNamedParam sets the value of every :name placeholder of the query being constructed, the query fails when it
+// has no such placeholder.
+func (queryBuilder *QueryBuilder) SetNamedParam(name string, param interface{}) *QueryBuilder {
+	queryBuilder.namedParams[strings.TrimPrefix(name, ":")] = param
+
+	return queryBuilder
+}
+
+// GetParameters gets all defined query parameters for the query being constructed in placeholder order,
+// named params are placed wherever their :name placeholder appears.
 func (queryBuilder *QueryBuilder) GetParameters() []interface{} {
-	return queryBuilder.params
+	_, params, _ := queryBuilder.build()
+
+	return params
 }
 
 // GetSQL gets the complete SQL string formed by the current specifications of this QueryBuilder.
+// It renders the query every time and has no side effects, so the QueryBuilder may still be changed afterwards.
 func (queryBuilder *QueryBuilder) GetSQL() string {
-	sqlString := queryBuilder.sql
+	sqlString, _, _ := queryBuilder.build()
 
-	if sqlString != "" {
-		return sqlString
-	}
+	return sqlString
+}
+
+// build returns the SQL string with the placeholders of the dialect and its params in placeholder order.
+func (queryBuilder *QueryBuilder) build() (string, []interface{}, error) {
+	sqlString, params, err := queryBuilder.buildPositional()
+
+	return rebind(queryBuilder.dialect, sqlString), params, err
+}
 
-	queryType := queryBuilder.queryType
+// buildPositional returns the SQL string with ? placeholders only and its params in placeholder order,
+// the params set with SetParam are bound after the ones bound by the query parts.
+func (queryBuilder *QueryBuilder) buildPositional() (string, []interface{}, error) {
+	var sqlString string
+	var params []interface{}
 
-	switch queryType {
+	switch queryBuilder.queryType {
 	case Insert:
-		sqlString = queryBuilder.getSQLForInsert()
+		sqlString, params = queryBuilder.getSQLForInsert()
 	case Delete:
-		sqlString = queryBuilder.getSQLForDelete()
+		sqlString, params = queryBuilder.getSQLForDelete()
 	case Update:
-		sqlString = queryBuilder.getSQLForUpdate()
-	case Select:
-		sqlString = queryBuilder.getSQLForSelect()
+		sqlString, params = queryBuilder.getSQLForUpdate()
 	default:
-		sqlString = queryBuilder.getSQLForSelect()
+		sqlString, params = queryBuilder.getSQLForSelect()
 	}
-	queryBuilder.sql = sqlString
 
-	return cleanUpMessySQL(sqlString)
-}
+	withSql, withParams := queryBuilder.getSQLForWith()
+	sqlString = withSql + sqlString
+	params = append(withParams, params...)
 
-// getSQLForUpdate returns an update string in SQL.
-func (queryBuilder *QueryBuilder) getSQLForUpdate() string {
-	sqlString := "UPDATE "
+	err := checkNamedParams(sqlString, queryBuilder.namedParams)
 
-	table := ""
-	for _, v := range queryBuilder.sqlPartsFrom {
-		table = v.table + " " + v.alias
+	sqlString, params, bindErr := bindParams(sqlString, params, queryBuilder.params, queryBuilder.namedParams, queryBuilder.dialect)
+	if bindErr != nil {
+		err = bindErr
+	}
+	if err == nil {
+		err = queryBuilder.validate()
 	}
 
-	sortedKeys := make([]string, 0)
+	return cleanUpMessySQL(sqlString), params, err
+}
 
-	paramsTemp := make([]interface{}, 0)
+// validate returns the error recorded while building the query, if any, or the first error found in its parts.
+func (queryBuilder *QueryBuilder) validate() error {
+	if queryBuilder.err != nil {
+		return queryBuilder.err
+	}
 
-	sqlString += table + " SET "
+	if err := queryBuilder.validateKeyset(); err != nil {
+		return err
+	}
 
-	for _, v := range queryBuilder.sqlPartsSet {
-		sortedKeys = append(sortedKeys, v.key)
+	if err := queryBuilder.validateModification(); err != nil {
+		return err
+	}
 
-		sqlString += v.key + " = ? ,"
+	if err := queryBuilder.validateWith(); err != nil {
+		return err
+	}
 
-		paramsTemp = append(paramsTemp, v.val)
+	if err := queryBuilder.validateUnion(); err != nil {
+		return err
 	}
 
-	for _, v := range queryBuilder.params {
-		paramsTemp = append(paramsTemp, v)
+	if err := queryBuilder.validateHints(); err != nil {
+		return err
 	}
 
-	queryBuilder.params = paramsTemp
+	if err := queryBuilder.validateDialect(); err != nil {
+		return err
+	}
 
-	sqlString = sqlString[:len(sqlString)-1]
+	if err := queryBuilder.validateScopes(); err != nil {
+		return err
+	}
 
-	if whereStr := queryBuilder.sqlPartsWhere; whereStr != "" {
-		sqlString += " WHERE " + whereStr
+	return queryBuilder.validateLock()
+}
+
+// getSQLForUpdate returns an update string in SQL and its params, joined and comma separated tables are rendered
+// as a multi-table update.
+func (queryBuilder *QueryBuilder) getSQLForUpdate() (string, []interface{}) {
+	fromSql, params := queryBuilder.getFromClauses()
+
+	sqlString := "UPDATE " + queryBuilder.getSQLForOptimizerHints() + fromSql + " SET "
+
+	for _, v := range queryBuilder.sqlPartsSet {
+		sqlString += queryBuilder.quote(v.key) + " = " + boundPlaceholder + " ,"
+
+		params = append(params, v.val)
 	}
 
-	return sqlString
+	sqlString = sqlString[:len(sqlString)-1]
 
+	if where := queryBuilder.getWhere(); !where.IsEmpty() {
+		sqlString += " WHERE " + where.sql
+		params = append(params, where.params...)
+	}
+
+	return sqlString + queryBuilder.getSQLForOrderBy() + queryBuilder.getSQLForRowLimit(), params
 }
 
-// getSQLForJoins returns a join string in SQL.
-func (queryBuilder *QueryBuilder) getSQLForJoins() string {
+// getSQLForJoins returns a join string in SQL and its params.
+func (queryBuilder *QueryBuilder) getSQLForJoins() (string, []interface{}) {
 	sqlString := ""
+	params := make([]interface{}, 0)
 
 	if queryBuilder.flag != IsJoin {
-		return ""
+		return "", params
 	}
 
-	for _, v := range queryBuilder.sqlPartsJoin {
-		joinType := v.joinType
-		joinTable := v.joinTable
-		joinAlias := v.joinAlias
-		joinCondition := v.joinCondition
+	for i, v := range queryBuilder.sqlPartsJoin {
+		table := v.joinTable
+		if v.joinSubQuery != nil {
+			table = boundPlaceholder
+			params = append(params, v.joinSubQuery)
+		}
 
-		sqlString += " " + joinType + " JOIN " + joinTable + " " + joinAlias + " ON " + joinCondition
+		sqlString += " " + v.joinType + " JOIN " + queryBuilder.quoteTable(table, v.joinAlias)
+		sqlString += queryBuilder.getSQLForIndexHints(v.joinTable, v.joinAlias)
+
+		if len(v.joinUsing) > 0 {
+			sqlString += " USING (" + queryBuilder.quoteList(v.joinUsing) + ")"
+			continue
+		}
 
-		return sqlString
+		if condition := queryBuilder.getJoinCondition(i, v); !condition.IsEmpty() {
+			sqlString += " ON " + condition.sql
+			params = append(params, condition.params...)
+		}
 	}
 
-	return sqlString
+	return sqlString, params
 }
 
-// getFromClauses returns table or join sql string
-func (queryBuilder *QueryBuilder) getFromClauses() string {
-	tableSql := ""
+// getFromClauses returns the comma separated query roots followed by the joins in SQL and their params.
+func (queryBuilder *QueryBuilder) getFromClauses() (string, []interface{}) {
+	tables := make([]string, 0, len(queryBuilder.sqlPartsFrom))
+	params := make([]interface{}, 0)
 
 	for _, v := range queryBuilder.sqlPartsFrom {
-		tableSql = v.table + " " + v.alias
-		return tableSql + queryBuilder.getSQLForJoins()
+		table := v.table
+		if v.subQuery != nil {
+			table = boundPlaceholder
+			params = append(params, v.subQuery)
+		}
+
+		tables = append(tables, queryBuilder.quoteTable(table, v.alias)+queryBuilder.getSQLForIndexHints(v.table, v.alias))
 	}
 
-	return tableSql
+	if len(tables) == 0 {
+		return "", params
+	}
+
+	joinsSql, joinsParams := queryBuilder.getSQLForJoins()
+
+	return strings.Join(tables, ", ") + joinsSql, append(params, joinsParams...)
 }
 
-// getSQLForSelect returns a select string in SQL.
-func (queryBuilder *QueryBuilder) getSQLForSelect() string {
-	sqlString := "SELECT "
+// getSQLForSelect returns a select string in SQL and its params.
+func (queryBuilder *QueryBuilder) getSQLForSelect() (string, []interface{}) {
+	var sqlString string
+	var params []interface{}
+
+	if len(queryBuilder.sqlPartsUnion) > 0 {
+		sqlString, params = queryBuilder.getSQLForUnion()
+	} else {
+		sqlString, params = queryBuilder.getSQLForSelectCore()
+	}
+
+	return sqlString + queryBuilder.getSQLForOrderBy() + queryBuilder.getSQLForLimit() + queryBuilder.getSQLForLock(), params
+}
+
+// getSQLForSelectCore returns a select string in SQL without ORDER BY and LIMIT and its params.
+func (queryBuilder *QueryBuilder) getSQLForSelectCore() (string, []interface{}) {
+	return queryBuilder.getSQLForSelectCoreWhere(And(queryBuilder.getWhere(), queryBuilder.getKeysetCondition()))
+}
 
-	if selectStr := queryBuilder.sqlPartsSelect; selectStr != "" {
-		sqlString += selectStr
+// getSQLForSelectCoreWhere returns a select string in SQL without ORDER BY and LIMIT filtered by the given
+// condition and its params.
+func (queryBuilder *QueryBuilder) getSQLForSelectCoreWhere(where Expression) (string, []interface{}) {
+	sqlString := "SELECT " + queryBuilder.getSQLForOptimizerHints() + queryBuilder.getSQLForSelectModifiers()
+	params := make([]interface{}, 0)
+
+	selects := make([]string, 0, len(queryBuilder.sqlPartsSelect))
+	for _, v := range queryBuilder.sqlPartsSelect {
+		if !v.IsEmpty() {
+			selects = append(selects, v.sql)
+			params = append(params, v.params...)
+		}
 	}
+	sqlString += strings.Join(selects, ", ")
 
-	sqlString += " FROM " + queryBuilder.getFromClauses()
+	fromSql, fromParams := queryBuilder.getFromClauses()
+	sqlString += " FROM " + fromSql
+	params = append(params, fromParams...)
 
-	if whereStr := queryBuilder.sqlPartsWhere; whereStr != "" {
-		sqlString += " WHERE " + whereStr
+	if !where.IsEmpty() {
+		sqlString += " WHERE " + where.sql
+		params = append(params, where.params...)
 	}
 
-	if groupByStr := queryBuilder.sqlPartsGroupBy; groupByStr != "" {
-		sqlString += " GROUP BY " + groupByStr
+	if len(queryBuilder.sqlPartsGroupBy) > 0 {
+		columns := make([]string, 0, len(queryBuilder.sqlPartsGroupBy))
+		for _, v := range queryBuilder.sqlPartsGroupBy {
+			if v.raw {
+				columns = append(columns, v.column)
+			} else {
+				columns = append(columns, queryBuilder.quote(v.column))
+			}
+		}
+		sqlString += " GROUP BY " + strings.Join(columns, ", ")
 	}
 
-	if havingStr := queryBuilder.sqlPartsHaving; havingStr != "" {
-		sqlString += " HAVING " + havingStr
+	if having := queryBuilder.sqlPartsHaving; !having.IsEmpty() {
+		sqlString += " HAVING " + having.sql
+		params = append(params, having.params...)
 	}
 
-	if queryBuilder.hasSort == HasSort {
-		sqlString += " ORDER BY "
+	return sqlString, params
+}
+
+// getSQLForUnion returns the parenthesized query and the union parts combined in SQL and their params,
+// the query itself is the first part only when it has a query root. The union parts are rendered with the dialect
+// of the query and their errors are returned by validateUnion.
+func (queryBuilder *QueryBuilder) getSQLForUnion() (string, []interface{}) {
+	sqlString := ""
+	params := make([]interface{}, 0)
 
-		for _, v := range queryBuilder.sqlPartsOrderBy {
-			sqlString += v.sort + " " + v.order + ", "
+	if len(queryBuilder.sqlPartsFrom) > 0 {
+		coreSql, coreParams := queryBuilder.getSQLForSelectCore()
+		sqlString = "(" + coreSql + ")"
+		params = append(params, coreParams...)
+	}
+
+	for _, v := range queryBuilder.sqlPartsUnion {
+		if sqlString != "" {
+			sqlString += " " + v.unionType + " "
 		}
-		sqlString = sqlString[:len(sqlString)-2]
+		partSql, partParams, _ := v.query.withDialect(queryBuilder.dialect).buildPositional()
+		sqlString += "(" + bindPlaceholders(partSql, len(partParams)) + ")"
+		params = append(params, partParams...)
 	}
 
-	if queryBuilder.isLimitQuery() {
-		sqlString += " LIMIT " + strconv.Itoa(queryBuilder.firstResult) + "," + strconv.Itoa(queryBuilder.maxResults)
+	return sqlString, params
+}
+
+// validateUnion returns the first error found while building the union parts.
+func (queryBuilder *QueryBuilder) validateUnion() error {
+	for _, v := range queryBuilder.sqlPartsUnion {
+		if _, _, err := v.query.withDialect(queryBuilder.dialect).buildPositional(); err != nil {
+			return err
+		}
 	}
 
-	return sqlString
+	return nil
 }
 
-// getSQLForDelete returns an delete string in SQL.
-func (queryBuilder *QueryBuilder) getSQLForDelete() string {
-	sqlString := "DELETE "
+// getSQLForOrderBy returns an order by string in SQL.
+func (queryBuilder *QueryBuilder) getSQLForOrderBy() string {
+	if queryBuilder.hasSort != HasSort {
+		return ""
+	}
 
-	for _, v := range queryBuilder.sqlPartsFrom {
-		sqlString += " FROM " + v.table
-		if whereStr := queryBuilder.sqlPartsWhere; whereStr != "" {
-			sqlString += " WHERE " + whereStr
-		}
+	sqlString := " ORDER BY "
 
-		return sqlString
+	for _, v := range queryBuilder.sqlPartsOrderBy {
+		sqlString += queryBuilder.getSortSQL(v) + " " + v.order + ", "
 	}
 
-	return sqlString
+	return sqlString[:len(sqlString)-2]
 }
 
-// getSQLForInsert returns an insert string in SQL.
-func (queryBuilder *QueryBuilder) getSQLForInsert() string {
-	sqlString := "INSERT INTO" + " "
+// getSortSQL returns the sort of an order by part in SQL, quoted unless it is raw.
+func (queryBuilder *QueryBuilder) getSortSQL(orderBy OrderBySqlParts) string {
+	if orderBy.raw {
+		return orderBy.sort
+	}
 
-	for _, v := range queryBuilder.sqlPartsFrom {
-		tableSql := v.table
-		sqlString += tableSql + " ("
+	return queryBuilder.quote(orderBy.sort)
+}
 
-		sortedKeys := make([]string, 0)
+// getSQLForLimit returns a limit string in SQL.
+func (queryBuilder *QueryBuilder) getSQLForLimit() string {
+	if !queryBuilder.isLimitQuery() {
+		return ""
+	}
+
+	return queryBuilder.dialect.Limit(queryBuilder.firstResult, queryBuilder.maxResults)
+}
 
-		values := ""
+// getSQLForRowLimit returns a limit string in SQL without offset, as update and delete queries accept it.
+func (queryBuilder *QueryBuilder) getSQLForRowLimit() string {
+	if !queryBuilder.isLimitQuery() {
+		return ""
+	}
+
+	return " LIMIT " + strconv.Itoa(queryBuilder.maxResults)
+}
 
-		params := make([]interface{}, 0)
+// getSQLForDelete returns an delete string in SQL and its params, aliased, joined and comma separated tables are
+// rendered as a multi-table delete from the delete targets.
+func (queryBuilder *QueryBuilder) getSQLForDelete() (string, []interface{}) {
+	if len(queryBuilder.sqlPartsFrom) == 0 {
+		return "DELETE ", nil
+	}
 
-		for _, v := range queryBuilder.sqlPartsValues {
-			sortedKeys = append(sortedKeys, v.key)
+	if column := queryBuilder.getSoftDeleteColumn(); column != "" {
+		return queryBuilder.getSQLForSoftDelete(column)
+	}
 
-			sqlString += v.key + ", "
-			values += "?, "
+	fromSql, params := queryBuilder.getFromClauses()
 
-			params = append(params, v.val)
+	sqlString := "DELETE " + queryBuilder.getSQLForOptimizerHints() + "FROM " + fromSql
+	if queryBuilder.isMultiTable() {
+		sqlString = "DELETE " + queryBuilder.getSQLForOptimizerHints() + queryBuilder.quoteList(queryBuilder.getDeleteTargets()) + " FROM " + fromSql
+	}
 
-		}
+	if where := queryBuilder.getWhere(); !where.IsEmpty() {
+		sqlString += " WHERE " + where.sql
+		params = append(params, where.params...)
+	}
 
-		queryBuilder.params = params
+	return sqlString + queryBuilder.getSQLForOrderBy() + queryBuilder.getSQLForRowLimit(), params
+}
 
-		sqlString = sqlString[:len(sqlString)-2]
-		values = values[:len(values)-2]
-		sqlString += ") VALUES (" + values + ")"
+// getDeleteTargets returns the tables set with DeleteTargets, or the alias or name of the first table otherwise.
+func (queryBuilder *QueryBuilder) getDeleteTargets() []string {
+	if len(queryBuilder.deleteTargets) > 0 {
+		return queryBuilder.deleteTargets
+	}
 
-		return sqlString
+	target := queryBuilder.sqlPartsFrom[0].alias
+	if target == "" {
+		target = queryBuilder.sqlPartsFrom[0].table
 	}
 
-	return sqlString
+	return []string{target}
+}
+
+// isMultiTable returns whether an update or delete query ranges over more than a single table,
+// aliased delete queries and the ones with delete targets are rendered as multi-table too.
+func (queryBuilder *QueryBuilder) isMultiTable() bool {
+	if len(queryBuilder.sqlPartsFrom) > 1 || queryBuilder.flag == IsJoin {
+		return true
+	}
+
+	if queryBuilder.queryType != Delete || len(queryBuilder.sqlPartsFrom) == 0 {
+		return false
+	}
+
+	return queryBuilder.sqlPartsFrom[0].alias != "" || len(queryBuilder.deleteTargets) > 0
+}
+
+// validateModification returns an error when an update or delete query has an ORDER BY or LIMIT that MySQL
+// does not accept.
+func (queryBuilder *QueryBuilder) validateModification() error {
+	if queryBuilder.queryType != Update && queryBuilder.queryType != Delete {
+		return nil
+	}
+
+	if !queryBuilder.isLimitQuery() && queryBuilder.hasSort != HasSort {
+		return nil
+	}
+
+	if queryBuilder.isMultiTable() {
+		return fmt.Errorf("ORDER BY and LIMIT are only supported by single table update and delete queries")
+	}
+
+	if queryBuilder.isLimitQuery() && queryBuilder.firstResult > 0 {
+		return fmt.Errorf("update and delete queries do not support a LIMIT offset, got %d", queryBuilder.firstResult)
+	}
+
+	return nil
+}
+
+// getSQLForInsert returns an insert string in SQL with every row and its params.
+func (queryBuilder *QueryBuilder) getSQLForInsert() (string, []interface{}) {
+	return queryBuilder.getSQLForInsertRows(queryBuilder.getInsertRows())
 }
 
 // isLimitQuery returns is a limited Query
@@ -437,40 +928,51 @@ func (queryBuilder *QueryBuilder) isLimitQuery() bool {
 
 // ExecuteQuery executes a query that returns rows
 func (queryBuilder *QueryBuilder) ExecuteQuery(query string) (*sql.Rows, error) {
-	if queryBuilder.params != nil {
-		rows, err := queryBuilder.database.Query(query, queryBuilder.params...)
+	return queryBuilder.ExecuteQueryContext(context.Background(), query)
+}
 
-		return rows, err
+// ExecuteQueryContext executes a query that returns rows, the query is cancelled when the context is done.
+func (queryBuilder *QueryBuilder) ExecuteQueryContext(ctx context.Context, query string) (*sql.Rows, error) {
+	_, params, err := queryBuilder.build()
+	if err != nil {
+		return nil, err
 	}
 
-	rows, err := queryBuilder.database.Query(query, nil)
+	queryBuilder.checkPlan(ctx, query, params)
+
+	rows, err := queryBuilder.database.QueryContext(ctx, query, params...)
 
 	return rows, err
 }
 
 // ExecuteQueryAndGetRowsMap executes a query that returns rows map
 func (queryBuilder *QueryBuilder) ExecuteQueryAndGetRowsMap(query string) (map[int]map[string]Field, error) {
-	if queryBuilder.params != nil {
-		rows, err := queryBuilder.database.Query(query, queryBuilder.params...)
-		if err != nil {
-			return nil, err
-		}
-
-		return getRowsMap(rows), err
-	}
+	return queryBuilder.ExecuteQueryAndGetRowsMapContext(context.Background(), query)
+}
 
-	rows, err := queryBuilder.database.Query(query, nil)
+// ExecuteQueryAndGetRowsMapContext executes a query that returns rows map, the query is cancelled when the
+// context is done.
+func (queryBuilder *QueryBuilder) ExecuteQueryAndGetRowsMapContext(ctx context.Context, query string) (map[int]map[string]Field, error) {
+	rows, err := queryBuilder.ExecuteQueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	return getRowsMap(rows), err
+	return getRowsMap(rows)
 }
 
-// getRowsMap returns rows map
-func getRowsMap(rows *sql.Rows) map[int]map[string]Field {
-	columns, _ := rows.Columns()
-	columnTypes, _ := rows.ColumnTypes()
+// getRowsMap returns rows map and closes the rows
+func getRowsMap(rows *sql.Rows) (map[int]map[string]Field, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
 	values := make([]interface{}, len(columns))
 	columnPointers := make([]interface{}, len(columns))
 
@@ -483,7 +985,7 @@ func getRowsMap(rows *sql.Rows) map[int]map[string]Field {
 		}
 
 		if err := rows.Scan(columnPointers...); err != nil {
-			panic(err)
+			return nil, err
 		}
 
 		record := map[string]Field{}
@@ -497,13 +999,22 @@ func getRowsMap(rows *sql.Rows) map[int]map[string]Field {
 		resultId++
 	}
 
-	return result
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 // Query executes a query that returns rows
 func (queryBuilder *QueryBuilder) Query() (*sql.Rows, error) {
+	return queryBuilder.QueryContext(context.Background())
+}
+
+// QueryContext executes a query that returns rows, the query is cancelled when the context is done.
+func (queryBuilder *QueryBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
 	if queryBuilder.queryType == Select {
-		return queryBuilder.ExecuteQuery(queryBuilder.GetSQL())
+		return queryBuilder.ExecuteQueryContext(ctx, queryBuilder.GetSQL())
 	}
 
 	return nil, nil
@@ -511,51 +1022,99 @@ func (queryBuilder *QueryBuilder) Query() (*sql.Rows, error) {
 
 // QueryAssoc executes a query that returns rows map
 func (queryBuilder *QueryBuilder) QueryAssoc() (map[int]map[string]Field, error) {
+	return queryBuilder.QueryAssocContext(context.Background())
+}
+
+// QueryAssocContext executes a query that returns rows map, the query is cancelled when the context is done.
+func (queryBuilder *QueryBuilder) QueryAssocContext(ctx context.Context) (map[int]map[string]Field, error) {
 	if queryBuilder.queryType == Select {
-		return queryBuilder.ExecuteQueryAndGetRowsMap(queryBuilder.GetSQL())
+		return queryBuilder.ExecuteQueryAndGetRowsMapContext(ctx, queryBuilder.GetSQL())
 	}
 
 	return nil, nil
 }
 
-// prepareAndExecute creates a prepared statement for later queries or executions.
-func (queryBuilder *QueryBuilder) prepareAndExecute() sql.Result {
-	stmt, err := queryBuilder.database.Prepare(queryBuilder.GetSQL())
-	if err != nil {
-		panic(err)
+// prepareAndExecute creates a prepared statement and executes it with the params.
+// Builders of a Connection reuse the statements of its cache, which owns them, the rest close theirs once executed.
+func (queryBuilder *QueryBuilder) prepareAndExecute(ctx context.Context, query string, params []interface{}) (sql.Result, error) {
+	if queryBuilder.statements != nil {
+		stmt, release, err := queryBuilder.statements.prepare(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return stmt.ExecContext(ctx, params...)
 	}
-	queryBuilder.State = stmt
-	res, err := stmt.Exec(queryBuilder.params...)
+
+	stmt, err := queryBuilder.database.PrepareContext(ctx, query)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	defer stmt.Close()
 
-	return res
+	return stmt.ExecContext(ctx, params...)
 }
 
 // PrepareAndExecute creates a prepared statement for later queries or executions.
+// Insert queries exceeding the max statement size run in batches and return the first batch last insert id,
+// or 0 when the dialect does not support LastInsertId. The batches are not run in a transaction, when one fails
+// -1 is returned although the previous ones stay inserted. Use a builder of NewQueryBuilderTx to insert every row
+// or none.
 func (queryBuilder *QueryBuilder) PrepareAndExecute() (int64, error) {
+	return queryBuilder.PrepareAndExecuteContext(context.Background())
+}
+
+// PrepareAndExecuteContext creates a prepared statement for later queries or executions, the execution is
+// cancelled when the context is done.
+func (queryBuilder *QueryBuilder) PrepareAndExecuteContext(ctx context.Context) (int64, error) {
 	if queryBuilder.queryType == Insert {
-		res := queryBuilder.prepareAndExecute()
+		batches, err := queryBuilder.getInsertBatches()
+		if err != nil || len(batches) == 0 {
+			return -1, err
+		}
+
+		res, err := queryBuilder.prepareAndExecute(ctx, batches[0].sql, batches[0].params)
+		if err != nil {
+			return -1, err
+		}
+
+		for _, batch := range batches[1:] {
+			if _, err := queryBuilder.prepareAndExecute(ctx, batch.sql, batch.params); err != nil {
+				return -1, err
+			}
+		}
+
+		if !queryBuilder.dialect.Supports(LastInsertIdFeature) {
+			return 0, nil
+		}
+
 		return res.LastInsertId()
 	}
 
-	if queryBuilder.queryType == Delete {
-		res := queryBuilder.prepareAndExecute()
-		return res.RowsAffected()
+	if queryBuilder.queryType != Delete && queryBuilder.queryType != Update {
+		return -1, nil
 	}
 
-	if queryBuilder.queryType == Update {
-		res := queryBuilder.prepareAndExecute()
-		return res.RowsAffected()
+	query, params, err := queryBuilder.build()
+	if err != nil {
+		return -1, err
 	}
 
-	return -1, nil
+	queryBuilder.checkPlan(ctx, query, params)
+
+	res, err := queryBuilder.prepareAndExecute(ctx, query, params)
+	if err != nil {
+		return -1, err
+	}
+
+	return res.RowsAffected()
 }
 
 // Insert turns the query being built into an insert query that inserts into
 func (queryBuilder *QueryBuilder) Insert(table string) *QueryBuilder {
 	queryBuilder.queryType = Insert
+	queryBuilder.insertVerb = InsertInto
 	queryBuilder.setFromWrap(table, "")
 
 	return queryBuilder
@@ -569,14 +1128,67 @@ func (queryBuilder *QueryBuilder) Delete(table string) *QueryBuilder {
 	return queryBuilder
 }
 
+// DeleteFrom turns the query being built into a bulk delete query that ranges over an aliased table,
+// which can be joined to other tables to delete the rows of the delete targets.
+func (queryBuilder *QueryBuilder) DeleteFrom(table string, alias string) *QueryBuilder {
+	queryBuilder.queryType = Delete
+	queryBuilder.setFromWrap(table, alias)
+
+	return queryBuilder
+}
+
+// DeleteTargets returns QueryBuilder that sets the aliases, or names, of the tables whose rows are deleted by a
+// multi-table delete query, it defaults to the first table.
+func (queryBuilder *QueryBuilder) DeleteTargets(targets ...string) *QueryBuilder {
+	queryBuilder.deleteTargets = targets
+
+	return queryBuilder
+}
+
+// quoteList returns the quoted identifiers separated by commas.
+func (queryBuilder *QueryBuilder) quoteList(identifiers []string) string {
+	quoted := make([]string, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		quoted = append(quoted, queryBuilder.quote(identifier))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
 // setFromWrap wraps sqlParts `from`
 func (queryBuilder *QueryBuilder) setFromWrap(table string, alias string) {
-	queryBuilder.sqlPartsFrom = append(queryBuilder.sqlPartsFrom, FromSqlParts{table, alias})
+	queryBuilder.sqlPartsFrom = append(queryBuilder.sqlPartsFrom, FromSqlParts{table: table, alias: alias})
 }
 
+// cleanUpMessySQL collapses every run of whitespace into a single space, keeping the line breaks that end line
+// comments so that they do not swallow the rest of the statement.
 func cleanUpMessySQL(sql string) string {
+	lines := make([]string, 0, 1)
+	start := 0
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i)
+		case c == '-' && strings.HasPrefix(sql[i:], "-- "), c == '#':
+			i = skipUntil(sql, i, "\n")
+			lines = append(lines, sql[start:i])
+			start = i + 1
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			i = skipUntil(sql, i+2, "*/") + 1
+		}
+	}
+
+	if start < len(sql) {
+		lines = append(lines, sql[start:])
+	}
+
 	space := regexp.MustCompile(`\s+`)
-	sql = space.ReplaceAllString(sql, " ")
+	for i, line := range lines {
+		lines[i] = strings.Trim(space.ReplaceAllString(line, " "), " ")
+	}
 
-	return strings.Trim(sql, " ")
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
 }
@@ -0,0 +1,165 @@
+package mysql
+
+// Clone returns a copy of the QueryBuilder that can be changed without affecting the original one,
+// so one base query can produce several variants. Subqueries are shared, not copied.
+func (queryBuilder *QueryBuilder) Clone() *QueryBuilder {
+	clone := *queryBuilder
+	clone.State = nil
+
+	clone.sqlPartsGroupBy = append([]GroupBySqlParts{}, queryBuilder.sqlPartsGroupBy...)
+	clone.sqlPartsSelect = append([]Expression{}, queryBuilder.sqlPartsSelect...)
+	clone.selectModifiers = append([]string{}, queryBuilder.selectModifiers...)
+	clone.optimizerHints = append([]string{}, queryBuilder.optimizerHints...)
+	clone.sqlPartsIndexHint = append([]IndexHintSqlParts{}, queryBuilder.sqlPartsIndexHint...)
+	clone.insertColumns = append([]string{}, queryBuilder.insertColumns...)
+	clone.deleteTargets = append([]string{}, queryBuilder.deleteTargets...)
+	clone.conflictColumns = append([]string{}, queryBuilder.conflictColumns...)
+	clone.params = append([]interface{}{}, queryBuilder.params...)
+	clone.sqlPartsFrom = append([]FromSqlParts{}, queryBuilder.sqlPartsFrom...)
+	clone.sqlPartsOrderBy = append([]OrderBySqlParts{}, queryBuilder.sqlPartsOrderBy...)
+	clone.sqlPartsValues = append([]ValuesSqlParts{}, queryBuilder.sqlPartsValues...)
+	clone.sqlPartsSet = append([]SetSqlParts{}, queryBuilder.sqlPartsSet...)
+	clone.sqlPartsJoin = append([]JoinSqlParts{}, queryBuilder.sqlPartsJoin...)
+	clone.sqlPartsRows = make([][]interface{}, 0, len(queryBuilder.sqlPartsRows))
+	for _, row := range queryBuilder.sqlPartsRows {
+		clone.sqlPartsRows = append(clone.sqlPartsRows, append([]interface{}{}, row...))
+	}
+	clone.sqlPartsOnDuplicate = append([]OnDuplicateSqlParts{}, queryBuilder.sqlPartsOnDuplicate...)
+	clone.sqlPartsUnion = append([]UnionSqlParts{}, queryBuilder.sqlPartsUnion...)
+	clone.sqlPartsWith = append([]WithSqlParts{}, queryBuilder.sqlPartsWith...)
+	clone.sqlPartsAfter = append([]interface{}{}, queryBuilder.sqlPartsAfter...)
+
+	clone.namedParams = make(map[string]interface{}, len(queryBuilder.namedParams))
+	for name, param := range queryBuilder.namedParams {
+		clone.namedParams[name] = param
+	}
+
+	if queryBuilder.withoutScopes != nil {
+		clone.withoutScopes = make(map[string]bool, len(queryBuilder.withoutScopes))
+		for name := range queryBuilder.withoutScopes {
+			clone.withoutScopes[name] = true
+		}
+	}
+
+	if queryBuilder.allowedIdentifiers != nil {
+		clone.allowedIdentifiers = make(map[string]bool, len(queryBuilder.allowedIdentifiers))
+		for identifier := range queryBuilder.allowedIdentifiers {
+			clone.allowedIdentifiers[identifier] = true
+		}
+	}
+
+	return &clone
+}
+
+// ResetSelect returns QueryBuilder without any select item.
+func (queryBuilder *QueryBuilder) ResetSelect() *QueryBuilder {
+	queryBuilder.sqlPartsSelect = nil
+
+	return queryBuilder
+}
+
+// ResetSelectModifiers returns QueryBuilder without any select modifier.
+func (queryBuilder *QueryBuilder) ResetSelectModifiers() *QueryBuilder {
+	queryBuilder.selectModifiers = nil
+
+	return queryBuilder
+}
+
+// ResetHints returns QueryBuilder without any optimizer nor index hint.
+func (queryBuilder *QueryBuilder) ResetHints() *QueryBuilder {
+	queryBuilder.optimizerHints = nil
+	queryBuilder.sqlPartsIndexHint = nil
+
+	return queryBuilder
+}
+
+// ResetJoin returns QueryBuilder without any join.
+func (queryBuilder *QueryBuilder) ResetJoin() *QueryBuilder {
+	queryBuilder.flag = IsDefault
+	queryBuilder.sqlPartsJoin = make([]JoinSqlParts, 0)
+
+	return queryBuilder
+}
+
+// ResetWhere returns QueryBuilder without any restriction.
+func (queryBuilder *QueryBuilder) ResetWhere() *QueryBuilder {
+	queryBuilder.sqlPartsWhere = Expression{}
+
+	return queryBuilder
+}
+
+// ResetGroupBy returns QueryBuilder without any grouping.
+func (queryBuilder *QueryBuilder) ResetGroupBy() *QueryBuilder {
+	queryBuilder.sqlPartsGroupBy = nil
+
+	return queryBuilder
+}
+
+// ResetHaving returns QueryBuilder without any restriction over the groups.
+func (queryBuilder *QueryBuilder) ResetHaving() *QueryBuilder {
+	queryBuilder.sqlPartsHaving = Expression{}
+
+	return queryBuilder
+}
+
+// ResetOrderBy returns QueryBuilder without any ordering nor the keyset values set with After.
+func (queryBuilder *QueryBuilder) ResetOrderBy() *QueryBuilder {
+	queryBuilder.hasSort = ""
+	queryBuilder.sqlPartsOrderBy = make([]OrderBySqlParts, 0)
+	queryBuilder.sqlPartsAfter = nil
+
+	return queryBuilder
+}
+
+// ResetLimit returns QueryBuilder without first result nor max results.
+func (queryBuilder *QueryBuilder) ResetLimit() *QueryBuilder {
+	queryBuilder.firstResult = 0
+	queryBuilder.maxResults = -1
+
+	return queryBuilder
+}
+
+// ResetUnion returns QueryBuilder without any query combined with Union or UnionAll.
+func (queryBuilder *QueryBuilder) ResetUnion() *QueryBuilder {
+	queryBuilder.sqlPartsUnion = nil
+
+	return queryBuilder
+}
+
+// ResetWith returns QueryBuilder without any common table expression.
+func (queryBuilder *QueryBuilder) ResetWith() *QueryBuilder {
+	queryBuilder.sqlPartsWith = nil
+
+	return queryBuilder
+}
+
+// ResetLock returns QueryBuilder without any row locking clause.
+func (queryBuilder *QueryBuilder) ResetLock() *QueryBuilder {
+	queryBuilder.lockMode = ""
+	queryBuilder.lockOption = ""
+
+	return queryBuilder
+}
+
+// ResetSet returns QueryBuilder without any value set by an update query.
+func (queryBuilder *QueryBuilder) ResetSet() *QueryBuilder {
+	queryBuilder.sqlPartsSet = make([]SetSqlParts, 0)
+
+	return queryBuilder
+}
+
+// ResetValues returns QueryBuilder without any value nor row inserted by an insert query.
+func (queryBuilder *QueryBuilder) ResetValues() *QueryBuilder {
+	queryBuilder.sqlPartsValues = make([]ValuesSqlParts, 0)
+	queryBuilder.sqlPartsRows = nil
+
+	return queryBuilder
+}
+
+// ResetParams returns QueryBuilder without the params set with SetParam and SetNamedParam.
+func (queryBuilder *QueryBuilder) ResetParams() *QueryBuilder {
+	queryBuilder.params = []interface{}{}
+	queryBuilder.namedParams = map[string]interface{}{}
+
+	return queryBuilder
+}
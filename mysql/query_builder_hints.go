@@ -0,0 +1,209 @@
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The select modifiers, rendered in the order MySQL expects them.
+const (
+	Distinct         = "DISTINCT"
+	DistinctRow      = "DISTINCTROW"
+	HighPriority     = "HIGH_PRIORITY"
+	StraightJoin     = "STRAIGHT_JOIN"
+	SqlSmallResult   = "SQL_SMALL_RESULT"
+	SqlBigResult     = "SQL_BIG_RESULT"
+	SqlBufferResult  = "SQL_BUFFER_RESULT"
+	SqlNoCache       = "SQL_NO_CACHE"
+	SqlCalcFoundRows = "SQL_CALC_FOUND_ROWS"
+)
+
+// The index hint types.
+const (
+	UseIndex    = "USE INDEX"
+	ForceIndex  = "FORCE INDEX"
+	IgnoreIndex = "IGNORE INDEX"
+)
+
+// selectModifierRanks records the position of every select modifier.
+var selectModifierRanks = map[string]int{
+	Distinct:         0,
+	DistinctRow:      1,
+	HighPriority:     2,
+	StraightJoin:     3,
+	SqlSmallResult:   4,
+	SqlBigResult:     5,
+	SqlBufferResult:  6,
+	SqlNoCache:       7,
+	SqlCalcFoundRows: 8,
+}
+
+// Distinct returns QueryBuilder that removes the duplicated rows from the query result.
+func (queryBuilder *QueryBuilder) Distinct() *QueryBuilder {
+	return queryBuilder.SelectModifiers(Distinct)
+}
+
+// StraightJoin returns QueryBuilder that joins the tables in the order they are given.
+func (queryBuilder *QueryBuilder) StraightJoin() *QueryBuilder {
+	return queryBuilder.SelectModifiers(StraightJoin)
+}
+
+// SelectModifiers returns QueryBuilder that adds the given modifiers, such as SqlCalcFoundRows, to a select query.
+func (queryBuilder *QueryBuilder) SelectModifiers(modifiers ...string) *QueryBuilder {
+	for _, modifier := range modifiers {
+		modifier = strings.ToUpper(strings.TrimSpace(modifier))
+		if _, ok := selectModifierRanks[modifier]; !ok {
+			queryBuilder.setError(fmt.Errorf("unknown select modifier %q", modifier))
+			continue
+		}
+
+		if !queryBuilder.hasSelectModifier(modifier) {
+			queryBuilder.selectModifiers = append(queryBuilder.selectModifiers, modifier)
+		}
+	}
+
+	return queryBuilder
+}
+
+// OptimizerHint returns QueryBuilder that adds an optimizer hint, such as "BKA(h)", to the query.
+func (queryBuilder *QueryBuilder) OptimizerHint(hint string) *QueryBuilder {
+	if strings.Contains(hint, "*/") {
+		queryBuilder.setError(fmt.Errorf("optimizer hint %q must not close the hint comment", hint))
+		return queryBuilder
+	}
+
+	queryBuilder.optimizerHints = append(queryBuilder.optimizerHints, hint)
+
+	return queryBuilder
+}
+
+// MaxExecutionTime returns QueryBuilder that aborts the select query when it runs longer than the given milliseconds.
+func (queryBuilder *QueryBuilder) MaxExecutionTime(milliseconds int) *QueryBuilder {
+	return queryBuilder.OptimizerHint("MAX_EXECUTION_TIME(" + strconv.Itoa(milliseconds) + ")")
+}
+
+// UseIndex returns QueryBuilder that hints the table with the given alias, or name, to use one of the indexes.
+func (queryBuilder *QueryBuilder) UseIndex(table string, indexes ...string) *QueryBuilder {
+	return queryBuilder.addIndexHint(table, UseIndex, indexes)
+}
+
+// ForceIndex returns QueryBuilder that forces the table with the given alias, or name, to use one of the indexes.
+func (queryBuilder *QueryBuilder) ForceIndex(table string, indexes ...string) *QueryBuilder {
+	return queryBuilder.addIndexHint(table, ForceIndex, indexes)
+}
+
+// IgnoreIndex returns QueryBuilder that hints the table with the given alias, or name, to ignore the indexes.
+func (queryBuilder *QueryBuilder) IgnoreIndex(table string, indexes ...string) *QueryBuilder {
+	return queryBuilder.addIndexHint(table, IgnoreIndex, indexes)
+}
+
+// addIndexHint records an index hint of the table with the given alias, or name.
+func (queryBuilder *QueryBuilder) addIndexHint(table string, hintType string, indexes []string) *QueryBuilder {
+	hint := IndexHintSqlParts{table: table, hintType: hintType, indexes: indexes}
+	queryBuilder.sqlPartsIndexHint = append(queryBuilder.sqlPartsIndexHint, hint)
+
+	return queryBuilder
+}
+
+// hasSelectModifier returns whether the query has the given select modifier.
+func (queryBuilder *QueryBuilder) hasSelectModifier(modifier string) bool {
+	for _, v := range queryBuilder.selectModifiers {
+		if v == modifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSQLForSelectModifiers returns the select modifiers string in SQL followed by a space.
+func (queryBuilder *QueryBuilder) getSQLForSelectModifiers() string {
+	if len(queryBuilder.selectModifiers) == 0 {
+		return ""
+	}
+
+	ranked := make([]string, len(selectModifierRanks))
+	for _, modifier := range queryBuilder.selectModifiers {
+		ranked[selectModifierRanks[modifier]] = modifier
+	}
+
+	modifiers := make([]string, 0, len(queryBuilder.selectModifiers))
+	for _, modifier := range ranked {
+		if modifier != "" {
+			modifiers = append(modifiers, modifier)
+		}
+	}
+
+	return strings.Join(modifiers, " ") + " "
+}
+
+// getSQLForOptimizerHints returns the optimizer hints comment in SQL followed by a space.
+func (queryBuilder *QueryBuilder) getSQLForOptimizerHints() string {
+	if len(queryBuilder.optimizerHints) == 0 {
+		return ""
+	}
+
+	return "/*+ " + strings.Join(queryBuilder.optimizerHints, " ") + " */ "
+}
+
+// getSQLForIndexHints returns the index hints string in SQL of the given table, preceded by a space.
+func (queryBuilder *QueryBuilder) getSQLForIndexHints(table string, alias string) string {
+	sqlString := ""
+
+	for _, v := range queryBuilder.sqlPartsIndexHint {
+		if v.table == alias || (alias == "" && v.table == table) {
+			sqlString += " " + v.hintType + " (" + queryBuilder.quoteList(v.indexes) + ")"
+		}
+	}
+
+	return sqlString
+}
+
+// validateHints returns an error when the select modifiers are used by other queries or an index hint is given
+// for a table the query does not range over, or by a single-table delete query, whose syntax has no index hints.
+func (queryBuilder *QueryBuilder) validateHints() error {
+	if len(queryBuilder.selectModifiers) > 0 && queryBuilder.queryType != Select {
+		return fmt.Errorf("select modifiers are only supported by select queries")
+	}
+
+	if len(queryBuilder.sqlPartsIndexHint) > 0 && queryBuilder.queryType == Delete &&
+		!queryBuilder.isMultiTable() && queryBuilder.getSoftDeleteColumn() == "" {
+		return fmt.Errorf("index hints are not supported by single-table delete queries")
+	}
+
+	if queryBuilder.hasSelectModifier(Distinct) && queryBuilder.hasSelectModifier(DistinctRow) {
+		return fmt.Errorf("%s and %s are mutually exclusive", Distinct, DistinctRow)
+	}
+
+	for _, v := range queryBuilder.sqlPartsIndexHint {
+		if !queryBuilder.hasTable(v.table) {
+			return fmt.Errorf("%s given for %q, which is not a table of the query", v.hintType, v.table)
+		}
+
+		for _, index := range v.indexes {
+			if err := ValidateIdentifier(index); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasTable returns whether the query ranges over a table with the given alias, or name when it has no alias.
+func (queryBuilder *QueryBuilder) hasTable(table string) bool {
+	for _, v := range queryBuilder.sqlPartsFrom {
+		if v.alias == table || (v.alias == "" && v.table == table) {
+			return true
+		}
+	}
+
+	for _, v := range queryBuilder.sqlPartsJoin {
+		if v.joinAlias == table || (v.joinAlias == "" && v.joinTable == table) {
+			return true
+		}
+	}
+
+	return false
+}
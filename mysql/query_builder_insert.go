@@ -0,0 +1,272 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// The insert verbs.
+const (
+	InsertInto       = "INSERT INTO"
+	InsertIgnoreInto = "INSERT IGNORE INTO"
+	ReplaceInto      = "REPLACE INTO"
+)
+
+// DefaultMaxStatementSize is the default max size in bytes of every statement of a batched insert,
+// it matches the MySQL 5.7 default max_allowed_packet.
+const DefaultMaxStatementSize = 4 << 20
+
+// maxPlaceholders is the max number of placeholders MySQL accepts in a prepared statement.
+const maxPlaceholders = 65535
+
+// estimatedParamSize is the size in bytes estimated for params that are neither strings nor bytes.
+const estimatedParamSize = 8
+
+// InsertIgnore turns the query being built into an insert query that skips the rows that would duplicate a key.
+func (queryBuilder *QueryBuilder) InsertIgnore(table string) *QueryBuilder {
+	queryBuilder.Insert(table)
+	queryBuilder.insertVerb = InsertIgnoreInto
+
+	return queryBuilder
+}
+
+// Replace turns the query being built into a replace query that deletes the rows that would duplicate a key
+// before inserting the new ones.
+func (queryBuilder *QueryBuilder) Replace(table string) *QueryBuilder {
+	queryBuilder.Insert(table)
+	queryBuilder.insertVerb = ReplaceInto
+
+	return queryBuilder
+}
+
+// Columns returns QueryBuilder that sets the columns of the rows added with AddRow in a multi-row insert query.
+func (queryBuilder *QueryBuilder) Columns(columns ...string) *QueryBuilder {
+	queryBuilder.insertColumns = columns
+
+	for _, row := range queryBuilder.sqlPartsRows {
+		queryBuilder.checkRowLength(row)
+	}
+
+	return queryBuilder
+}
+
+// AddRow returns QueryBuilder that adds a row of values, in Columns order, to a multi-row insert query.
+// The query fails when the row does not have a value for each column.
+func (queryBuilder *QueryBuilder) AddRow(values ...interface{}) *QueryBuilder {
+	queryBuilder.sqlPartsRows = append(queryBuilder.sqlPartsRows, values)
+	queryBuilder.checkRowLength(values)
+
+	return queryBuilder
+}
+
+// checkRowLength records an error when the columns are set and the row does not have a value for each of them.
+func (queryBuilder *QueryBuilder) checkRowLength(row []interface{}) {
+	if len(queryBuilder.insertColumns) > 0 && len(row) != len(queryBuilder.insertColumns) {
+		queryBuilder.setError(fmt.Errorf("insert rows need %d values, one for each column, got %d",
+			len(queryBuilder.insertColumns), len(row)))
+	}
+}
+
+// OnDuplicateKeyUpdate returns QueryBuilder that updates the given columns with the inserted values
+// when a row would duplicate a key.
+func (queryBuilder *QueryBuilder) OnDuplicateKeyUpdate(columns ...string) *QueryBuilder {
+	for _, column := range columns {
+		queryBuilder.sqlPartsOnDuplicate = append(queryBuilder.sqlPartsOnDuplicate, OnDuplicateSqlParts{key: column})
+	}
+
+	return queryBuilder
+}
+
+// OnDuplicateKeyUpdateExpr returns QueryBuilder that sets a column to the given expression
+// when a row would duplicate a key.
+func (queryBuilder *QueryBuilder) OnDuplicateKeyUpdateExpr(column string, expression Expression) *QueryBuilder {
+	update := OnDuplicateSqlParts{key: column, expression: &expression}
+	queryBuilder.sqlPartsOnDuplicate = append(queryBuilder.sqlPartsOnDuplicate, update)
+
+	return queryBuilder
+}
+
+// SetMaxStatementSize returns QueryBuilder that sets the max size in bytes of every statement executed for
+// a multi-row insert query, rows are split in as many statements as needed.
+func (queryBuilder *QueryBuilder) SetMaxStatementSize(maxStatementSize int) *QueryBuilder {
+	queryBuilder.maxStatementSize = maxStatementSize
+
+	return queryBuilder
+}
+
+// PrepareAndExecuteBatches executes an insert query split in as many statements as needed to keep them under
+// the max statement size and returns the rows affected by each of them.
+// The batches are not run in a transaction, when one fails the previous ones stay inserted and their rows
+// affected are returned with the error. Use a builder of NewQueryBuilderTx to insert every row or none.
+func (queryBuilder *QueryBuilder) PrepareAndExecuteBatches() ([]int64, error) {
+	return queryBuilder.PrepareAndExecuteBatchesContext(context.Background())
+}
+
+// PrepareAndExecuteBatchesContext executes an insert query in batches like PrepareAndExecuteBatches,
+// the pending batches are cancelled when the context is done.
+func (queryBuilder *QueryBuilder) PrepareAndExecuteBatchesContext(ctx context.Context) ([]int64, error) {
+	if queryBuilder.queryType != Insert {
+		return nil, fmt.Errorf("batched execution is only supported by insert queries")
+	}
+
+	batches, err := queryBuilder.getInsertBatches()
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected := make([]int64, 0, len(batches))
+	for _, batch := range batches {
+		res, err := queryBuilder.prepareAndExecute(ctx, batch.sql, batch.params)
+		if err != nil {
+			return rowsAffected, err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return rowsAffected, err
+		}
+		rowsAffected = append(rowsAffected, affected)
+	}
+
+	return rowsAffected, nil
+}
+
+// getSQLForInsertRows returns an insert string in SQL for the given rows and its params.
+func (queryBuilder *QueryBuilder) getSQLForInsertRows(rows [][]interface{}) (string, []interface{}) {
+	sqlString := queryBuilder.insertVerb + " "
+
+	for _, v := range queryBuilder.sqlPartsFrom {
+		columns := queryBuilder.getInsertColumns()
+		sqlString += queryBuilder.quote(v.table) + " (" + queryBuilder.quoteList(columns) + ") VALUES "
+
+		rowSql := "(" + strings.TrimSuffix(strings.Repeat(boundPlaceholder+", ", len(columns)), ", ") + ")"
+		params := make([]interface{}, 0, len(rows)*len(columns))
+
+		for i, row := range rows {
+			if i > 0 {
+				sqlString += ", "
+			}
+			sqlString += rowSql
+			params = append(params, row...)
+		}
+
+		if len(queryBuilder.sqlPartsOnDuplicate) > 0 {
+			updates := make([]string, 0, len(queryBuilder.sqlPartsOnDuplicate))
+			for _, update := range queryBuilder.sqlPartsOnDuplicate {
+				column := queryBuilder.quote(update.key)
+				if update.expression == nil {
+					updates = append(updates, column+" = "+queryBuilder.dialect.InsertedValue(column))
+					continue
+				}
+				updates = append(updates, column+" = "+update.expression.sql)
+				params = append(params, update.expression.params...)
+			}
+			conflictColumns := make([]string, 0, len(queryBuilder.conflictColumns))
+			for _, column := range queryBuilder.conflictColumns {
+				conflictColumns = append(conflictColumns, queryBuilder.quote(column))
+			}
+			sqlString += queryBuilder.dialect.Upsert(conflictColumns, updates)
+		}
+
+		return sqlString, params
+	}
+
+	return sqlString, nil
+}
+
+// getInsertColumns returns the columns set with Columns, or the keys set with Value otherwise.
+func (queryBuilder *QueryBuilder) getInsertColumns() []string {
+	if len(queryBuilder.insertColumns) > 0 {
+		return queryBuilder.insertColumns
+	}
+
+	columns := make([]string, 0, len(queryBuilder.sqlPartsValues))
+	for _, v := range queryBuilder.sqlPartsValues {
+		columns = append(columns, v.key)
+	}
+
+	return columns
+}
+
+// getInsertRows returns the rows added with AddRow, or the single row set with Value otherwise.
+func (queryBuilder *QueryBuilder) getInsertRows() [][]interface{} {
+	if len(queryBuilder.insertColumns) > 0 {
+		return queryBuilder.sqlPartsRows
+	}
+
+	row := make([]interface{}, 0, len(queryBuilder.sqlPartsValues))
+	for _, v := range queryBuilder.sqlPartsValues {
+		row = append(row, v.val)
+	}
+
+	return [][]interface{}{row}
+}
+
+// getInsertBatches returns the statements needed to insert every row without exceeding the max statement size
+// nor the max number of placeholders of a statement.
+func (queryBuilder *QueryBuilder) getInsertBatches() ([]statement, error) {
+	if err := queryBuilder.validate(); err != nil {
+		return nil, err
+	}
+
+	rows := queryBuilder.getInsertRows()
+	baseSql, baseParams := queryBuilder.getSQLForInsertRows(nil)
+	baseSize := len(baseSql) + paramsSize(baseParams)
+	columnsCount := len(queryBuilder.getInsertColumns())
+
+	batches := make([]statement, 0)
+	start, size := 0, baseSize
+
+	for i, row := range rows {
+		rowSize := 2*len(row) + 2 + paramsSize(row)
+		placeholders := (i-start+1)*columnsCount + len(baseParams)
+
+		if i > start && (size+rowSize > queryBuilder.maxStatementSize || placeholders > maxPlaceholders) {
+			batch, err := queryBuilder.newInsertStatement(rows[start:i])
+			if err != nil {
+				return nil, err
+			}
+			batches = append(batches, batch)
+			start, size = i, baseSize
+		}
+
+		size += rowSize
+	}
+
+	if start < len(rows) {
+		batch, err := queryBuilder.newInsertStatement(rows[start:])
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
+
+// newInsertStatement returns the statement inserting the given rows.
+func (queryBuilder *QueryBuilder) newInsertStatement(rows [][]interface{}) (statement, error) {
+	sqlString, params := queryBuilder.getSQLForInsertRows(rows)
+
+	sqlString, params, err := bindParams(sqlString, params, queryBuilder.params, queryBuilder.namedParams, queryBuilder.dialect)
+
+	return statement{sql: rebind(queryBuilder.dialect, cleanUpMessySQL(sqlString)), params: params}, err
+}
+
+// paramsSize returns the estimated size in bytes of the given params.
+func paramsSize(params []interface{}) int {
+	size := 0
+	for _, param := range params {
+		switch v := param.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		default:
+			size += estimatedParamSize
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,92 @@
+package mysql
+
+import "fmt"
+
+// The row locking clauses.
+const (
+	ForUpdate       = "FOR UPDATE"
+	ForShare        = "FOR SHARE"
+	LockInShareMode = "LOCK IN SHARE MODE"
+)
+
+// The row locking modifiers, only supported by FOR UPDATE and FOR SHARE since MySQL 8.0.
+const (
+	SkipLocked = "SKIP LOCKED"
+	NoWait     = "NOWAIT"
+)
+
+// ForUpdate returns QueryBuilder that locks the selected rows for update until the transaction ends.
+func (queryBuilder *QueryBuilder) ForUpdate() *QueryBuilder {
+	queryBuilder.lockMode = ForUpdate
+
+	return queryBuilder
+}
+
+// ForShare returns QueryBuilder that locks the selected rows against updates until the transaction ends,
+// it requires MySQL 8.0.
+func (queryBuilder *QueryBuilder) ForShare() *QueryBuilder {
+	queryBuilder.lockMode = ForShare
+
+	return queryBuilder
+}
+
+// LockInShareMode returns QueryBuilder that locks the selected rows against updates until the transaction ends,
+// it is the ForShare variant supported by MySQL 5.7.
+func (queryBuilder *QueryBuilder) LockInShareMode() *QueryBuilder {
+	queryBuilder.lockMode = LockInShareMode
+
+	return queryBuilder
+}
+
+// SkipLocked returns QueryBuilder that skips the rows locked by other transactions instead of waiting for them.
+func (queryBuilder *QueryBuilder) SkipLocked() *QueryBuilder {
+	queryBuilder.lockOption = SkipLocked
+
+	return queryBuilder
+}
+
+// NoWait returns QueryBuilder that fails right away instead of waiting for the rows locked by other transactions.
+func (queryBuilder *QueryBuilder) NoWait() *QueryBuilder {
+	queryBuilder.lockOption = NoWait
+
+	return queryBuilder
+}
+
+// getSQLForLock returns a row locking string in SQL.
+func (queryBuilder *QueryBuilder) getSQLForLock() string {
+	if queryBuilder.lockMode == "" {
+		return ""
+	}
+
+	sqlString := " " + queryBuilder.lockMode
+	if queryBuilder.lockOption != "" {
+		sqlString += " " + queryBuilder.lockOption
+	}
+
+	return sqlString
+}
+
+// validateLock returns an error when the row locking clause is not supported by the query.
+func (queryBuilder *QueryBuilder) validateLock() error {
+	if queryBuilder.lockMode == "" && queryBuilder.lockOption == "" {
+		return nil
+	}
+
+	if queryBuilder.queryType != Select {
+		return fmt.Errorf("row locking is only supported by select queries")
+	}
+
+	if queryBuilder.lockMode == "" {
+		return fmt.Errorf("%s needs %s or %s", queryBuilder.lockOption, ForUpdate, ForShare)
+	}
+
+	if len(queryBuilder.sqlPartsUnion) > 0 {
+		return fmt.Errorf("row locking is not supported by union queries")
+	}
+
+	if queryBuilder.lockMode == LockInShareMode && queryBuilder.lockOption != "" {
+		return fmt.Errorf("%s is not supported by %s, use %s", queryBuilder.lockOption, LockInShareMode, ForShare)
+	}
+
+	return nil
+}
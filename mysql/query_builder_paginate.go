@@ -0,0 +1,230 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Page records the rows of a page of a select query together with the total rows of the whole query.
+type Page struct {
+	Rows    map[int]map[string]Field
+	Total   int64
+	Page    int
+	PerPage int
+	// Cursor records the values of the ORDER BY columns of the last row, to be given to After to fetch the next
+	// page in keyset mode. It is nil when the page is empty or any of the sort columns is not in the result.
+	Cursor []interface{}
+}
+
+// TotalPages returns the number of pages needed to list every row of the query.
+func (page *Page) TotalPages() int {
+	if page.PerPage < 1 {
+		return 0
+	}
+
+	return int((page.Total + int64(page.PerPage) - 1) / int64(page.PerPage))
+}
+
+// After returns QueryBuilder that only keeps the rows sorted after the row with the given ORDER BY values,
+// in the same order as the OrderBy calls. It lets Paginate fetch deep pages without an offset.
+func (queryBuilder *QueryBuilder) After(lastSortValues ...interface{}) *QueryBuilder {
+	queryBuilder.sqlPartsAfter = lastSortValues
+
+	return queryBuilder
+}
+
+// Paginate executes a select query limited to the rows of the given 1-based page and counts the rows of the
+// whole query with a COUNT(*) rewrite that drops ORDER BY and LIMIT.
+// In keyset mode, set with After, the page number is only informative and the rows follow the given values.
+func (queryBuilder *QueryBuilder) Paginate(page int, perPage int) (*Page, error) {
+	return queryBuilder.PaginateContext(context.Background(), page, perPage)
+}
+
+// PaginateContext executes a select query like Paginate, the queries are cancelled when the context is done.
+func (queryBuilder *QueryBuilder) PaginateContext(ctx context.Context, page int, perPage int) (*Page, error) {
+	if queryBuilder.queryType != Select {
+		return nil, fmt.Errorf("pagination is only supported by select queries")
+	}
+	if perPage < 1 {
+		return nil, fmt.Errorf("pagination needs at least one row per page, got %d", perPage)
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	countSql, countParams, err := queryBuilder.buildCount()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := queryBuilder.database.QueryRowContext(ctx, countSql, countParams...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	firstResult := (page - 1) * perPage
+	if len(queryBuilder.sqlPartsAfter) > 0 {
+		firstResult = 0
+	}
+	pageQuery := queryBuilder.Clone().SetFirstResult(firstResult).SetMaxResults(perPage)
+
+	query, _, err := pageQuery.build()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pageQuery.ExecuteQueryAndGetRowsMapContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{
+		Rows:    rows,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+		Cursor:  queryBuilder.getCursor(rows),
+	}, nil
+}
+
+// buildCount returns the COUNT(*) rewrite of the select query with positional placeholders only and its params.
+func (queryBuilder *QueryBuilder) buildCount() (string, []interface{}, error) {
+	sqlString, params, legacy := queryBuilder.getSQLForCount()
+
+	withSql, withParams := queryBuilder.getSQLForWith()
+	sqlString = withSql + sqlString
+	params = append(withParams, params...)
+
+	sqlString, params, err := bindParams(sqlString, params, legacy, queryBuilder.namedParams, queryBuilder.dialect)
+	if err == nil {
+		err = queryBuilder.err
+	}
+	if err == nil {
+		err = queryBuilder.validateKeyset()
+	}
+	if err == nil {
+		err = queryBuilder.validateScopes()
+	}
+
+	return rebind(queryBuilder.dialect, cleanUpMessySQL(sqlString)), params, err
+}
+
+// getSQLForCount returns a select string in SQL that counts the rows of the query, ignoring the keyset condition,
+// its params and the params set with SetParam it binds. Grouped, distinct and combined queries are counted as
+// derived table, the others drop the select list together with the params set with SetParam for it.
+func (queryBuilder *QueryBuilder) getSQLForCount() (string, []interface{}, []interface{}) {
+	if len(queryBuilder.sqlPartsUnion) > 0 {
+		unionSql, params := queryBuilder.getSQLForUnion()
+
+		return "SELECT COUNT(*) FROM (" + unionSql + ") " + queryBuilder.quote("count_query"), params, queryBuilder.params
+	}
+
+	if len(queryBuilder.sqlPartsGroupBy) > 0 || !queryBuilder.sqlPartsHaving.IsEmpty() || queryBuilder.isDistinct() {
+		coreSql, params := queryBuilder.getSQLForSelectCoreWhere(queryBuilder.getWhere())
+
+		return "SELECT COUNT(*) FROM (" + coreSql + ") " + queryBuilder.quote("count_query"), params, queryBuilder.params
+	}
+
+	legacy := queryBuilder.params
+	for _, v := range queryBuilder.sqlPartsSelect {
+		dropped := countPlaceholders(v.sql)
+		if dropped > len(legacy) {
+			dropped = len(legacy)
+		}
+		legacy = legacy[dropped:]
+	}
+
+	fromSql, params := queryBuilder.getFromClauses()
+	sqlString := "SELECT " + queryBuilder.getSQLForOptimizerHints() + "COUNT(*) FROM " + fromSql
+
+	if where := queryBuilder.getWhere(); !where.IsEmpty() {
+		sqlString += " WHERE " + where.sql
+		params = append(params, where.params...)
+	}
+
+	return sqlString, params, legacy
+}
+
+// isDistinct returns whether the query selects distinct rows only.
+func (queryBuilder *QueryBuilder) isDistinct() bool {
+	for _, modifier := range queryBuilder.selectModifiers {
+		if modifier == Distinct || modifier == DistinctRow {
+			return true
+		}
+	}
+
+	if len(queryBuilder.sqlPartsSelect) == 0 {
+		return false
+	}
+
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(queryBuilder.sqlPartsSelect[0].sql)), "DISTINCT ")
+}
+
+// getKeysetCondition returns the condition that keeps the rows sorted after the values set with After,
+// or an empty Expression when there are none.
+func (queryBuilder *QueryBuilder) getKeysetCondition() Expression {
+	if len(queryBuilder.sqlPartsAfter) == 0 || len(queryBuilder.sqlPartsAfter) != len(queryBuilder.sqlPartsOrderBy) {
+		return Expression{}
+	}
+
+	alternatives := make([]string, 0, len(queryBuilder.sqlPartsOrderBy))
+	params := make([]interface{}, 0)
+
+	for i, v := range queryBuilder.sqlPartsOrderBy {
+		conditions := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, queryBuilder.getSortSQL(queryBuilder.sqlPartsOrderBy[j])+" = ?")
+			params = append(params, queryBuilder.sqlPartsAfter[j])
+		}
+
+		operator := " > ?"
+		if v.order == "DESC" {
+			operator = " < ?"
+		}
+		conditions = append(conditions, queryBuilder.getSortSQL(v)+operator)
+		params = append(params, queryBuilder.sqlPartsAfter[i])
+
+		alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+	}
+
+	return Expr(strings.Join(alternatives, " OR "), params...)
+}
+
+// validateKeyset returns an error when the values set with After do not match the ORDER BY columns.
+func (queryBuilder *QueryBuilder) validateKeyset() error {
+	if len(queryBuilder.sqlPartsAfter) == 0 {
+		return nil
+	}
+
+	if len(queryBuilder.sqlPartsUnion) > 0 {
+		return fmt.Errorf("keyset pagination is not supported by union queries")
+	}
+
+	if len(queryBuilder.sqlPartsAfter) != len(queryBuilder.sqlPartsOrderBy) {
+		return fmt.Errorf("keyset pagination needs a value for each of the %d ORDER BY columns, got %d",
+			len(queryBuilder.sqlPartsOrderBy), len(queryBuilder.sqlPartsAfter))
+	}
+
+	return nil
+}
+
+// getCursor returns the values of the ORDER BY columns of the last of the given rows.
+func (queryBuilder *QueryBuilder) getCursor(rows map[int]map[string]Field) []interface{} {
+	last, ok := rows[len(rows)-1]
+	if !ok || len(queryBuilder.sqlPartsOrderBy) == 0 {
+		return nil
+	}
+
+	cursor := make([]interface{}, 0, len(queryBuilder.sqlPartsOrderBy))
+	for _, v := range queryBuilder.sqlPartsOrderBy {
+		parts := strings.Split(unquoteIdentifier(v.sort), ".")
+		field, ok := last[parts[len(parts)-1]]
+		if v.raw || !ok {
+			return nil
+		}
+		cursor = append(cursor, field.RawVal())
+	}
+
+	return cursor
+}
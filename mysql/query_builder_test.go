@@ -1,10 +1,13 @@
 package mysql_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/atrapalo/go-base/mysql"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
 	"testing"
 )
 
@@ -14,7 +17,7 @@ func Test_select(t *testing.T) {
 
 	queryBuilder := mysql.NewQueryBuilder(db)
 	sql := queryBuilder.Select("id, title").From("posts", "").GetSQL()
-	expectedSql := "SELECT id, title FROM posts"
+	expectedSql := "SELECT id, title FROM `posts`"
 	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %s want %s", sql, expectedSql)
 
 	rows := sqlmock.NewRows([]string{"id", "title", "body"}).
@@ -56,7 +59,7 @@ func joinTestCommonParts(t *testing.T, joinFlag string) {
 			SetMaxResults(3).
 			InnerJoin("user", "u", "u.uid = p.uid").
 			GetSQL()
-		expectedSql = "SELECT p.id, p.title FROM posts p INNER JOIN user u ON u.uid = p.uid LIMIT 0,3"
+		expectedSql = "SELECT p.id, p.title FROM `posts` `p` INNER JOIN `user` `u` ON u.uid = p.uid LIMIT 0,3"
 		break
 	case "LEFT":
 		sql = queryBuilder.
@@ -66,7 +69,7 @@ func joinTestCommonParts(t *testing.T, joinFlag string) {
 			SetMaxResults(3).
 			LeftJoin("user", "u", "u.uid = p.uid").
 			GetSQL()
-		expectedSql = "SELECT p.id, p.title FROM posts p LEFT JOIN user u ON u.uid = p.uid LIMIT 0,3"
+		expectedSql = "SELECT p.id, p.title FROM `posts` `p` LEFT JOIN `user` `u` ON u.uid = p.uid LIMIT 0,3"
 		break
 	case "RIGHT":
 		sql = queryBuilder.
@@ -76,7 +79,7 @@ func joinTestCommonParts(t *testing.T, joinFlag string) {
 			SetMaxResults(3).
 			RightJoin("user", "u", "u.uid = p.uid").
 			GetSQL()
-		expectedSql = "SELECT p.id, p.title FROM posts p RIGHT JOIN user u ON u.uid = p.uid LIMIT 0,3"
+		expectedSql = "SELECT p.id, p.title FROM `posts` `p` RIGHT JOIN `user` `u` ON u.uid = p.uid LIMIT 0,3"
 		break
 	}
 
@@ -109,7 +112,7 @@ func Test_get_sql(t *testing.T) {
 		OrderBy("uid", "DESC").
 		GetSQL()
 
-	expectedSql := "SELECT uid, username, created, textVal, price, name FROM userinfo WHERE username = ? AND department = ? ORDER BY uid DESC LIMIT 0,3"
+	expectedSql := "SELECT uid, username, created, textVal, price, name FROM `userinfo` WHERE username = ? AND department = ? ORDER BY `uid` DESC LIMIT 0,3"
 	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
 	assert.Equal(t, []interface{}{"john", "DT"}, queryBuilder.GetParameters())
 	assert.Equal(t, 3, queryBuilder.GetMaxResults())
@@ -126,7 +129,7 @@ func Test_get_sql(t *testing.T) {
 		Having("num > 1").
 		GetSQL()
 
-	expectedSql2 := "SELECT u.uid, u.username, p.address, count(*) as num FROM userinfo u RIGHT JOIN profile p ON u.uid = p.uid HAVING num > 1 LIMIT 0,3"
+	expectedSql2 := "SELECT u.uid, u.username, p.address, count(*) as num FROM `userinfo` `u` RIGHT JOIN `profile` `p` ON u.uid = p.uid HAVING num > 1 LIMIT 0,3"
 	assert.Equalf(t, expectedSql2, sql2, "returned unexpected sql: got %v want %v", sql2, expectedSql2)
 }
 
@@ -136,7 +139,7 @@ func Test_delete(t *testing.T) {
 
 	queryBuilder := mysql.NewQueryBuilder(db)
 	sql := queryBuilder.Delete("userinfo").Where("uid = ?").SetParam(7).GetSQL()
-	expectedSql := "DELETE FROM userinfo WHERE uid = ?"
+	expectedSql := "DELETE FROM `userinfo` WHERE uid = ?"
 
 	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
 }
@@ -153,7 +156,710 @@ func Test_update(t *testing.T) {
 		SetParam(1).
 		GetSQL()
 
-	expectedSql := "UPDATE userinfo u SET u.username = ? WHERE u.uid = ?"
+	expectedSql := "UPDATE `userinfo` `u` SET `u`.`username` = ? WHERE u.uid = ?"
 	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
 	assert.Equal(t, []interface{}{"john2", 1}, queryBuilder.GetParameters())
 }
+
+func Test_multiple_joins(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db)
+	sql := queryBuilder.
+		Select("b.id, h.name, c.name, s.name").
+		From("booking", "b").
+		InnerJoin("hotel", "h", "h.id = b.hotel_id").
+		LeftJoin("city", "c", "c.id = h.city_id").
+		RightJoin("supplier", "s", "s.id = b.supplier_id").
+		InnerJoinUsing("booking_status", "bs", "status_id").
+		GetSQL()
+
+	expectedSql := "SELECT b.id, h.name, c.name, s.name FROM `booking` `b` INNER JOIN `hotel` `h` ON h.id = b.hotel_id " +
+		"LEFT JOIN `city` `c` ON c.id = h.city_id RIGHT JOIN `supplier` `s` ON s.id = b.supplier_id " +
+		"INNER JOIN `booking_status` `bs` USING (`status_id`)"
+	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
+}
+
+func Test_multiple_from_roots_and_derived_tables(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db)
+	sql := queryBuilder.
+		Select("u.uid, p.address, t.total").
+		From("userinfo", "u").
+		From("profile", "p").
+		LeftJoin("(SELECT uid, COUNT(*) AS total FROM orders GROUP BY uid)", "t", "t.uid = p.uid").
+		LeftJoinUsing("settings", "", "uid", "lang").
+		Where("u.uid = p.uid").
+		GetSQL()
+
+	expectedSql := "SELECT u.uid, p.address, t.total FROM `userinfo` `u`, `profile` `p` " +
+		"LEFT JOIN (SELECT uid, COUNT(*) AS total FROM orders GROUP BY uid) `t` ON t.uid = p.uid " +
+		"LEFT JOIN `settings` USING (`uid`, `lang`) WHERE u.uid = p.uid"
+	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
+}
+
+func Test_where_expressions(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db)
+	sql := queryBuilder.
+		Select("id, name").
+		From("hotel", "").
+		Where("city_id = ?", 8).
+		AndWhereExpr(mysql.In("category", 4, 5)).
+		OrWhere("featured = ?", true).
+		GroupBy("id").
+		Having("COUNT(*) > ?", 1).
+		SetParam("extra").
+		GetSQL()
+
+	expectedSql := "SELECT id, name FROM `hotel` WHERE ((city_id = ?) AND (category IN (?, ?))) OR (featured = ?) " +
+		"GROUP BY `id` HAVING COUNT(*) > ?"
+	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
+	assert.Equal(t, []interface{}{8, 4, 5, true, 1, "extra"}, queryBuilder.GetParameters())
+
+	queryBuilder2 := mysql.NewQueryBuilder(db)
+	sql2 := queryBuilder2.
+		Update("hotel", "").
+		Set("name", "Arts").
+		AndWhereExpr(mysql.Eq("id", 3)).
+		GetSQL()
+
+	expectedSql2 := "UPDATE `hotel` SET `name` = ? WHERE id = ?"
+	assert.Equalf(t, expectedSql2, sql2, "returned unexpected sql: got %v want %v", sql2, expectedSql2)
+	assert.Equal(t, []interface{}{"Arts", 3}, queryBuilder2.GetParameters())
+}
+
+func Test_named_params(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db)
+	sql := queryBuilder.
+		Update("booking", "b").
+		Set("b.status", "cancelled").
+		Where("b.user_id = :user AND b.status <> 'a:b ?'").
+		AndWhere("b.created > ?", "2022-01-01").
+		OrWhere("b.owner_id = :user").
+		SetNamedParam("user", 42).
+		GetSQL()
+
+	expectedSql := "UPDATE `booking` `b` SET `b`.`status` = ? WHERE ((b.user_id = ? AND b.status <> 'a:b ?') " +
+		"AND (b.created > ?)) OR (b.owner_id = ?)"
+	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
+	assert.Equal(t, []interface{}{"cancelled", 42, "2022-01-01", 42}, queryBuilder.GetParameters())
+
+	_, err2 := mysql.NewQueryBuilder(db).
+		Delete("booking").
+		Where("id = :id").
+		PrepareAndExecute()
+	assert.EqualError(t, err2, "missing value for named param :id")
+
+	commented := mysql.NewQueryBuilder(db).Select("a -- first column\n, b").From("t", "").Where("x = :x").SetNamedParam("x", 1)
+	assert.Equal(t, "SELECT a -- first column\n, b FROM `t` WHERE x = ?", commented.GetSQL())
+	assert.Equal(t, []interface{}{1}, commented.GetParameters())
+
+	_, err3 := mysql.NewQueryBuilder(db).
+		Delete("booking").
+		Where("id = :id").
+		SetNamedParam("id", 1).
+		SetNamedParam("user", 42).
+		PrepareAndExecute()
+	assert.EqualError(t, err3, "unused value for named param :user")
+}
+
+func Test_multi_row_insert(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db)
+	sql := queryBuilder.
+		InsertIgnore("availability").
+		Columns("hotel_id", "day", "rooms").
+		AddRow(1, "2022-10-01", 5).
+		AddRow(2, "2022-10-01", 3).
+		GetSQL()
+
+	expectedSql := "INSERT IGNORE INTO `availability` (`hotel_id`, `day`, `rooms`) VALUES (?, ?, ?), (?, ?, ?)"
+	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
+	assert.Equal(t, []interface{}{1, "2022-10-01", 5, 2, "2022-10-01", 3}, queryBuilder.GetParameters())
+
+	queryBuilder2 := mysql.NewQueryBuilder(db)
+	sql2 := queryBuilder2.
+		Insert("availability").
+		Value("hotel_id", 1).
+		Value("rooms", 5).
+		OnDuplicateKeyUpdate("rooms").
+		OnDuplicateKeyUpdateExpr("updates", mysql.Expr("updates + ?", 1)).
+		GetSQL()
+
+	expectedSql2 := "INSERT INTO `availability` (`hotel_id`, `rooms`) VALUES (?, ?) " +
+		"ON DUPLICATE KEY UPDATE `rooms` = VALUES(`rooms`), `updates` = updates + ?"
+	assert.Equalf(t, expectedSql2, sql2, "returned unexpected sql: got %v want %v", sql2, expectedSql2)
+	assert.Equal(t, []interface{}{1, 5, 1}, queryBuilder2.GetParameters())
+
+	sql3 := mysql.NewQueryBuilder(db).Replace("availability").Value("hotel_id", 1).GetSQL()
+	assert.Equal(t, "REPLACE INTO `availability` (`hotel_id`) VALUES (?)", sql3)
+
+	_, err2 := mysql.NewQueryBuilder(db).Insert("availability").Columns("hotel_id", "rooms").AddRow(1, 5).AddRow(2).PrepareAndExecute()
+	assert.EqualError(t, err2, "insert rows need 2 values, one for each column, got 1")
+
+	_, err3 := mysql.NewQueryBuilder(db).Insert("availability").AddRow(1, 5, 3).Columns("hotel_id", "rooms").PrepareAndExecute()
+	assert.EqualError(t, err3, "insert rows need 2 values, one for each column, got 3")
+}
+
+func Test_multi_row_insert_in_batches(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db).
+		Insert("availability").
+		Columns("hotel_id", "supplier").
+		SetMaxStatementSize(110)
+	for i := 1; i <= 5; i++ {
+		queryBuilder.AddRow(i, "supplier")
+	}
+
+	batchOfTwo := regexp.QuoteMeta("INSERT INTO `availability` (`hotel_id`, `supplier`) VALUES (?, ?), (?, ?)")
+	batchOfOne := regexp.QuoteMeta("INSERT INTO `availability` (`hotel_id`, `supplier`) VALUES (?, ?)")
+	mock.ExpectPrepare(batchOfTwo).WillBeClosed().ExpectExec().WithArgs(1, "supplier", 2, "supplier").WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectPrepare(batchOfTwo).WillBeClosed().ExpectExec().WithArgs(3, "supplier", 4, "supplier").WillReturnResult(sqlmock.NewResult(3, 2))
+	mock.ExpectPrepare(batchOfOne).WillBeClosed().ExpectExec().WithArgs(5, "supplier").WillReturnResult(sqlmock.NewResult(5, 1))
+
+	rowsAffected, err2 := queryBuilder.PrepareAndExecuteBatches()
+	assert.Nil(t, err2)
+	assert.Equal(t, []int64{2, 2, 1}, rowsAffected)
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_execution_errors_are_returned(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectPrepare("UPDATE `hotel`").ExpectExec().WillReturnError(errors.New("lock wait timeout"))
+	_, err2 := mysql.NewQueryBuilder(db).Update("hotel", "").Set("name", "Arts").PrepareAndExecute()
+	assert.EqualError(t, err2, "lock wait timeout")
+
+	mock.ExpectPrepare("DELETE FROM `hotel`").WillReturnError(errors.New("syntax error"))
+	_, err3 := mysql.NewQueryBuilder(db).Delete("hotel").PrepareAndExecute()
+	assert.EqualError(t, err3, "syntax error")
+
+	rows := sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, errors.New("connection lost"))
+	mock.ExpectQuery("SELECT id FROM `hotel`").WillReturnRows(rows)
+	result, err4 := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").QueryAssoc()
+	assert.Nil(t, result)
+	assert.EqualError(t, err4, "connection lost")
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
+
+func Test_execution_with_cancelled_context(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err2 := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").QueryAssocContext(ctx)
+	assert.ErrorIs(t, err2, context.Canceled)
+
+	_, err3 := mysql.NewQueryBuilder(db).Delete("hotel").PrepareAndExecuteContext(ctx)
+	assert.ErrorIs(t, err3, context.Canceled)
+}
+
+func Test_query_builder_in_transaction(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE inventory").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO `booking` (`hotel_id`) VALUES (?)")).
+		ExpectExec().WithArgs(3).WillReturnResult(sqlmock.NewResult(12, 1))
+	mock.ExpectCommit()
+
+	tx, err2 := db.Begin()
+	assert.Nil(t, err2)
+
+	_, err3 := tx.Exec("UPDATE inventory SET rooms = rooms - 1 WHERE hotel_id = ?", 3)
+	assert.Nil(t, err3)
+
+	id, err4 := mysql.NewQueryBuilder(tx).Insert("booking").Value("hotel_id", 3).PrepareAndExecute()
+	assert.Nil(t, err4)
+	assert.Equal(t, int64(12), id)
+	assert.Nil(t, tx.Commit())
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
+
+func Test_sub_queries(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	bookings := mysql.NewQueryBuilder(db).
+		Select("hotel_id, COUNT(*) AS total").
+		From("booking", "").
+		Where("created > ?", "2022-01-01").
+		GroupBy("hotel_id")
+	cities := mysql.NewQueryBuilder(db).Select("id").From("city", "").Where("country = :country").SetNamedParam("country", "ES")
+	lastReview := mysql.NewQueryBuilder(db).Select("MAX(created)").From("review", "r").Where("r.hotel_id = h.id AND r.score > ?", 7)
+	offers := mysql.NewQueryBuilder(db).Select("1").From("offer", "o").Where("o.hotel_id = h.id")
+
+	queryBuilder := mysql.NewQueryBuilder(db)
+	sql := queryBuilder.
+		Select("h.id, b.total").
+		SelectSubQuery(lastReview, "last_review").
+		From("hotel", "h").
+		InnerJoinSubQuery(bookings, "b", "b.hotel_id = h.id").
+		WhereExpr(mysql.In("h.city_id", cities)).
+		AndWhereExpr(mysql.Exists(offers)).
+		AndWhere("h.stars >= ?", 4).
+		GetSQL()
+
+	expectedSql := "SELECT h.id, b.total, (SELECT MAX(created) FROM `review` `r` WHERE r.hotel_id = h.id AND r.score > ?) AS last_review " +
+		"FROM `hotel` `h` INNER JOIN (SELECT hotel_id, COUNT(*) AS total FROM `booking` WHERE created > ? GROUP BY `hotel_id`) `b` ON b.hotel_id = h.id " +
+		"WHERE ((h.city_id IN (SELECT id FROM `city` WHERE country = ?)) AND (EXISTS (SELECT 1 FROM `offer` `o` WHERE o.hotel_id = h.id))) AND (h.stars >= ?)"
+	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
+	assert.Equal(t, []interface{}{7, "2022-01-01", "ES", 4}, queryBuilder.GetParameters())
+
+	queryBuilder2 := mysql.NewQueryBuilder(db)
+	sql2 := queryBuilder2.
+		Select("t.hotel_id").
+		FromSubQuery(bookings, "t").
+		Where("t.total > ?", 10).
+		GetSQL()
+
+	expectedSql2 := "SELECT t.hotel_id FROM (SELECT hotel_id, COUNT(*) AS total FROM `booking` WHERE created > ? GROUP BY `hotel_id`) `t` WHERE t.total > ?"
+	assert.Equalf(t, expectedSql2, sql2, "returned unexpected sql: got %v want %v", sql2, expectedSql2)
+	assert.Equal(t, []interface{}{"2022-01-01", 10}, queryBuilder2.GetParameters())
+
+	queryBuilder3 := mysql.NewQueryBuilder(db)
+	sql3 := queryBuilder3.
+		Select("h.id").
+		From("hotel", "h").
+		Where("h.city = ?").
+		SetParam("BCN").
+		AndWhereExpr(mysql.Exists(offers)).
+		AndWhereExpr(mysql.In("h.city_id", cities)).
+		AndWhere("h.stars >= ?").
+		SetParam(4).
+		GetSQL()
+
+	expectedSql3 := "SELECT h.id FROM `hotel` `h` WHERE (((h.city = ?) AND (EXISTS (SELECT 1 FROM `offer` `o` WHERE o.hotel_id = h.id))) " +
+		"AND (h.city_id IN (SELECT id FROM `city` WHERE country = ?))) AND (h.stars >= ?)"
+	assert.Equalf(t, expectedSql3, sql3, "returned unexpected sql: got %v want %v", sql3, expectedSql3)
+	assert.Equal(t, []interface{}{"BCN", "ES", 4}, queryBuilder3.GetParameters())
+}
+
+func Test_union(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	flights := mysql.NewQueryBuilder(db).Select("id, 'flight' AS type, price").From("flight", "").Where("destination = ?", "BCN")
+	packages := mysql.NewQueryBuilder(db).Select("id, 'package' AS type, price").From("package", "").Where("destination = ?", "BCN")
+
+	queryBuilder := mysql.NewQueryBuilder(db)
+	sql := queryBuilder.
+		Select("id, 'hotel' AS type, price").
+		From("hotel", "").
+		Where("city = ?", "BCN").
+		UnionAll(flights).
+		Union(packages).
+		OrderBy("price", "ASC").
+		SetMaxResults(10).
+		GetSQL()
+
+	expectedSql := "(SELECT id, 'hotel' AS type, price FROM `hotel` WHERE city = ?) " +
+		"UNION ALL (SELECT id, 'flight' AS type, price FROM `flight` WHERE destination = ?) " +
+		"UNION (SELECT id, 'package' AS type, price FROM `package` WHERE destination = ?) ORDER BY `price` ASC LIMIT 0,10"
+	assert.Equalf(t, expectedSql, sql, "returned unexpected sql: got %v want %v", sql, expectedSql)
+	assert.Equal(t, []interface{}{"BCN", "BCN", "BCN"}, queryBuilder.GetParameters())
+
+	rows := sqlmock.NewRows([]string{"id", "type", "price"}).AddRow(1, "flight", 30).AddRow(7, "hotel", 80)
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs("BCN", "BCN", "BCN").WillReturnRows(rows)
+	result, err2 := queryBuilder.QueryAssoc()
+	assert.Nil(t, err2)
+	assert.Len(t, result, 2)
+
+	sql2 := mysql.NewQueryBuilder(db).UnionAll(flights, packages).GetSQL()
+	expectedSql2 := "(SELECT id, 'flight' AS type, price FROM `flight` WHERE destination = ?) " +
+		"UNION ALL (SELECT id, 'package' AS type, price FROM `package` WHERE destination = ?)"
+	assert.Equalf(t, expectedSql2, sql2, "returned unexpected sql: got %v want %v", sql2, expectedSql2)
+
+	queryBuilder3 := mysql.NewQueryBuilder(db).
+		Select("id, 'hotel' AS type, price").
+		From("hotel", "").
+		Where("city = ?").
+		SetParam("MAD").
+		Union(flights)
+	expectedSql3 := "(SELECT id, 'hotel' AS type, price FROM `hotel` WHERE city = ?) " +
+		"UNION (SELECT id, 'flight' AS type, price FROM `flight` WHERE destination = ?)"
+	assert.Equal(t, expectedSql3, queryBuilder3.GetSQL())
+	assert.Equal(t, []interface{}{"MAD", "BCN"}, queryBuilder3.GetParameters())
+
+	broken := mysql.NewQueryBuilder(db).Select("id").From("flight", "").Where("destination = :destination")
+	_, err3 := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").Union(broken).QueryAssoc()
+	assert.EqualError(t, err3, "missing value for named param :destination")
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+}
+
+func Test_paginate(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	countSql := "SELECT COUNT(*) FROM `hotel` WHERE city = ?"
+	mock.ExpectQuery(regexp.QuoteMeta(countSql)).WithArgs("BCN").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(45))
+
+	pageSql := "SELECT id, stars FROM `hotel` WHERE city = ? ORDER BY `stars` DESC, `id` ASC LIMIT 20,10"
+	mock.ExpectQuery(regexp.QuoteMeta(pageSql)).WithArgs("BCN").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stars"}).AddRow(21, 4).AddRow(30, 3))
+
+	queryBuilder := mysql.NewQueryBuilder(db).
+		Select("id, stars").
+		From("hotel", "").
+		Where("city = ?", "BCN").
+		OrderBy("stars", "DESC").
+		OrderBy("id", "ASC")
+
+	page, err2 := queryBuilder.Paginate(3, 10)
+	assert.Nil(t, err2)
+	assert.Equal(t, "SELECT id, stars FROM `hotel` WHERE city = ? ORDER BY `stars` DESC, `id` ASC", queryBuilder.GetSQL())
+	assert.Equal(t, int64(45), page.Total)
+	assert.Equal(t, 5, page.TotalPages())
+	assert.Len(t, page.Rows, 2)
+	assert.Equal(t, []interface{}{3, 30}, page.Cursor)
+
+	keysetSql := "SELECT id, stars FROM `hotel` WHERE (city = ?) AND " +
+		"((`stars` < ?) OR (`stars` = ? AND `id` > ?)) ORDER BY `stars` DESC, `id` ASC LIMIT 0,10"
+	mock.ExpectQuery(regexp.QuoteMeta(countSql)).WithArgs("BCN").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(45))
+	mock.ExpectQuery(regexp.QuoteMeta(keysetSql)).WithArgs("BCN", 3, 3, 30).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stars"}))
+
+	page, err3 := mysql.NewQueryBuilder(db).
+		Select("id, stars").
+		From("hotel", "").
+		Where("city = ?", "BCN").
+		OrderBy("stars", "DESC").
+		OrderBy("id", "ASC").
+		After(3, 30).
+		Paginate(4, 10)
+	assert.Nil(t, err3)
+	assert.Len(t, page.Rows, 0)
+	assert.Nil(t, page.Cursor)
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+
+	_, err5 := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").OrderBy("id", "ASC").After(1, 2).Paginate(1, 10)
+	assert.EqualError(t, err5, "keyset pagination needs a value for each of the 1 ORDER BY columns, got 2")
+}
+
+func Test_paginate_with_params_set_with_set_param(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `hotel` WHERE city = ?")).WithArgs("BCN").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(12))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, IF(stars > ?, 'top', 'regular') AS category FROM `hotel` "+
+		"WHERE (city = ?) AND ((`id` > ?)) ORDER BY `id` ASC LIMIT 0,10")).
+		WithArgs(4, "BCN", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "category"}).AddRow(6, "top"))
+
+	page, err2 := mysql.NewQueryBuilder(db).
+		Select("id, IF(stars > ?, 'top', 'regular') AS category").
+		From("hotel", "").
+		Where("city = ?").
+		SetParam(4).
+		SetParam("BCN").
+		OrderBy("id", "ASC").
+		After(5).
+		Paginate(2, 10)
+	assert.Nil(t, err2)
+	assert.Equal(t, int64(12), page.Total)
+	assert.Len(t, page.Rows, 1)
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_paginate_counts_grouped_queries_as_derived_table(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	countSql := "SELECT COUNT(*) FROM (SELECT city, COUNT(*) AS total FROM `hotel` GROUP BY `city` HAVING total > ?) `count_query`"
+	mock.ExpectQuery(regexp.QuoteMeta(countSql)).WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("GROUP BY `city` HAVING total > ? ORDER BY `total` DESC LIMIT 0,25")).WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"city", "total"}).AddRow("BCN", 9).AddRow("MAD", 7))
+
+	page, err2 := mysql.NewQueryBuilder(db).
+		Select("city, COUNT(*) AS total").
+		From("hotel", "").
+		GroupBy("city").
+		Having("total > ?", 5).
+		OrderBy("total", "DESC").
+		Paginate(0, 25)
+	assert.Nil(t, err2)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Len(t, page.Rows, 2)
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_get_sql_has_no_side_effects(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db).Update("hotel", "").Set("name", "Arts").Where("id = ?", 1)
+	sql := queryBuilder.GetSQL()
+	assert.Equal(t, sql, queryBuilder.GetSQL())
+	assert.Equal(t, []interface{}{"Arts", 1}, queryBuilder.GetParameters())
+
+	mock.ExpectPrepare(regexp.QuoteMeta(sql)).ExpectExec().WithArgs("Arts", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err2 := queryBuilder.PrepareAndExecute()
+	assert.Nil(t, err2)
+
+	queryBuilder.Set("stars", 5)
+	expectedSql := "UPDATE `hotel` SET `name` = ? ,`stars` = ? WHERE id = ?"
+	assert.Equal(t, expectedSql, queryBuilder.GetSQL())
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_clone_and_reset(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	base := mysql.NewQueryBuilder(db).
+		Select("h.id, h.name").
+		From("hotel", "h").
+		InnerJoin("city", "c", "c.id = h.city_id").
+		Where("c.country = ?", "ES").
+		OrderBy("h.name", "ASC").
+		SetMaxResults(10)
+
+	byStars := base.Clone().AndWhere("h.stars >= ?", 4).ResetOrderBy().OrderBy("h.stars", "DESC")
+	count := base.Clone().ResetSelect().Select("COUNT(*)").ResetJoin().ResetOrderBy().ResetLimit()
+
+	expectedBase := "SELECT h.id, h.name FROM `hotel` `h` INNER JOIN `city` `c` ON c.id = h.city_id " +
+		"WHERE c.country = ? ORDER BY `h`.`name` ASC LIMIT 0,10"
+	assert.Equal(t, expectedBase, base.GetSQL())
+	assert.Equal(t, []interface{}{"ES"}, base.GetParameters())
+
+	expectedByStars := "SELECT h.id, h.name FROM `hotel` `h` INNER JOIN `city` `c` ON c.id = h.city_id " +
+		"WHERE (c.country = ?) AND (h.stars >= ?) ORDER BY `h`.`stars` DESC LIMIT 0,10"
+	assert.Equal(t, expectedByStars, byStars.GetSQL())
+	assert.Equal(t, []interface{}{"ES", 4}, byStars.GetParameters())
+
+	assert.Equal(t, "SELECT COUNT(*) FROM `hotel` `h` WHERE c.country = ?", count.GetSQL())
+
+	reset := base.Clone().ResetWhere().ResetParams()
+	assert.Equal(t, "SELECT h.id, h.name FROM `hotel` `h` INNER JOIN `city` `c` ON c.id = h.city_id "+
+		"ORDER BY `h`.`name` ASC LIMIT 0,10", reset.GetSQL())
+	assert.Empty(t, reset.GetParameters())
+
+	row := []interface{}{1, 5}
+	insert := mysql.NewQueryBuilder(db).Insert("availability").Columns("hotel_id", "rooms").AddRow(row...)
+	insertClone := insert.Clone()
+	row[1] = 3
+	assert.Equal(t, []interface{}{1, 3}, insert.GetParameters())
+	assert.Equal(t, []interface{}{1, 5}, insertClone.GetParameters())
+}
+
+func Test_multi_table_update_and_delete(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	update := mysql.NewQueryBuilder(db).
+		Update("booking", "b").
+		InnerJoin("hotel", "h", "h.id = b.hotel_id").
+		Set("b.status", "cancelled").
+		Where("h.closed = ?", 1)
+	expectedUpdate := "UPDATE `booking` `b` INNER JOIN `hotel` `h` ON h.id = b.hotel_id SET `b`.`status` = ? WHERE h.closed = ?"
+	assert.Equal(t, expectedUpdate, update.GetSQL())
+	assert.Equal(t, []interface{}{"cancelled", 1}, update.GetParameters())
+
+	deleteQuery := mysql.NewQueryBuilder(db).
+		DeleteFrom("booking", "b").
+		LeftJoin("hotel", "h", "h.id = b.hotel_id").
+		Where("h.id IS NULL")
+	expectedDelete := "DELETE `b` FROM `booking` `b` LEFT JOIN `hotel` `h` ON h.id = b.hotel_id WHERE h.id IS NULL"
+	assert.Equal(t, expectedDelete, deleteQuery.GetSQL())
+
+	deleteQuery.DeleteTargets("b", "h")
+	expectedDelete = "DELETE `b`, `h` FROM `booking` `b` LEFT JOIN `hotel` `h` ON h.id = b.hotel_id WHERE h.id IS NULL"
+	assert.Equal(t, expectedDelete, deleteQuery.GetSQL())
+
+	_, err2 := deleteQuery.SetMaxResults(100).PrepareAndExecute()
+	assert.EqualError(t, err2, "ORDER BY and LIMIT are only supported by single table update and delete queries")
+}
+
+func Test_single_table_update_and_delete_with_order_and_limit(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	purgeSql := "DELETE FROM `audit` WHERE created < ? ORDER BY `id` ASC LIMIT 1000"
+	mock.ExpectPrepare(regexp.QuoteMeta(purgeSql)).ExpectExec().WithArgs("2022-01-01").WillReturnResult(sqlmock.NewResult(0, 1000))
+
+	purge := mysql.NewQueryBuilder(db).
+		Delete("audit").
+		Where("created < ?", "2022-01-01").
+		OrderBy("id", "ASC").
+		SetMaxResults(1000)
+	affected, err2 := purge.PrepareAndExecute()
+	assert.Nil(t, err2)
+	assert.Equal(t, int64(1000), affected)
+
+	update := mysql.NewQueryBuilder(db).
+		Update("hotel", "").
+		Set("featured", 0).
+		OrderBy("updated", "DESC").
+		SetMaxResults(10)
+	assert.Equal(t, "UPDATE `hotel` SET `featured` = ? ORDER BY `updated` DESC LIMIT 10", update.GetSQL())
+
+	_, err3 := update.SetFirstResult(10).PrepareAndExecute()
+	assert.EqualError(t, err3, "update and delete queries do not support a LIMIT offset, got 10")
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+}
+
+func Test_row_locking(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectBegin()
+	tx, err2 := db.Begin()
+	assert.Nil(t, err2)
+
+	lockSql := "SELECT id, rooms FROM `availability` WHERE hotel_id = ? FOR UPDATE"
+	mock.ExpectQuery(regexp.QuoteMeta(lockSql)).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"id", "rooms"}).AddRow(1, 3))
+
+	rows, err3 := mysql.NewQueryBuilder(tx).Select("id, rooms").From("availability", "").Where("hotel_id = ?", 7).ForUpdate().QueryAssoc()
+	assert.Nil(t, err3)
+	assert.Len(t, rows, 1)
+
+	queue := mysql.NewQueryBuilder(tx).Select("id").From("job", "").SetMaxResults(10)
+	assert.Equal(t, "SELECT id FROM `job` LIMIT 0,10 FOR UPDATE SKIP LOCKED", queue.ForUpdate().SkipLocked().GetSQL())
+	assert.Equal(t, "SELECT id FROM `job` LIMIT 0,10 FOR SHARE NOWAIT", queue.ForShare().NoWait().GetSQL())
+	shared := mysql.NewQueryBuilder(tx).Select("id").From("job", "").LockInShareMode()
+	assert.Equal(t, "SELECT id FROM `job` LOCK IN SHARE MODE", shared.GetSQL())
+
+	_, err4 := queue.LockInShareMode().QueryAssoc()
+	assert.EqualError(t, err4, "NOWAIT is not supported by LOCK IN SHARE MODE, use FOR SHARE")
+
+	_, err5 := mysql.NewQueryBuilder(tx).Delete("job").ForUpdate().PrepareAndExecute()
+	assert.EqualError(t, err5, "row locking is only supported by select queries")
+
+	hotels := mysql.NewQueryBuilder(tx).Select("id").From("hotel", "")
+	_, err6 := mysql.NewQueryBuilder(tx).Select("id").From("apartment", "").Union(hotels).ForUpdate().QueryAssoc()
+	assert.EqualError(t, err6, "row locking is not supported by union queries")
+
+	err7 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err7, "there were unfulfilled expectations: %s", err7)
+}
+
+func Test_common_table_expressions(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	anchor := mysql.NewQueryBuilder(db).Select("id, parent_id, name").From("destination", "").Where("id = ?", 34)
+	children := mysql.NewQueryBuilder(db).
+		Select("d.id, d.parent_id, d.name").
+		From("destination", "d").
+		InnerJoin("tree", "t", "d.parent_id = t.id")
+
+	queryBuilder := mysql.NewQueryBuilder(db).
+		WithRecursive("tree", anchor.UnionAll(children)).
+		Select("id, name").
+		From("tree", "").
+		Where("name LIKE ?", "B%")
+
+	expectedSql := "WITH RECURSIVE `tree` AS ((SELECT id, parent_id, name FROM `destination` WHERE id = ?) " +
+		"UNION ALL (SELECT d.id, d.parent_id, d.name FROM `destination` `d` INNER JOIN `tree` `t` ON d.parent_id = t.id)) " +
+		"SELECT id, name FROM `tree` WHERE name LIKE ?"
+	assert.Equal(t, expectedSql, queryBuilder.GetSQL())
+	assert.Equal(t, []interface{}{34, "B%"}, queryBuilder.GetParameters())
+
+	mock.ExpectQuery(regexp.QuoteMeta(expectedSql)).WithArgs(34, "B%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(34, "Spain").AddRow(512, "Barcelona"))
+	rows, err2 := queryBuilder.QueryAssoc()
+	assert.Nil(t, err2)
+	assert.Len(t, rows, 2)
+
+	closed := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").Where("closed = ?", 1)
+	update := mysql.NewQueryBuilder(db).
+		With("closed_hotel", closed).
+		Update("booking", "b").
+		InnerJoin("closed_hotel", "c", "c.id = b.hotel_id").
+		Set("b.status", "cancelled")
+	expectedUpdate := "WITH `closed_hotel` AS (SELECT id FROM `hotel` WHERE closed = ?) " +
+		"UPDATE `booking` `b` INNER JOIN `closed_hotel` `c` ON c.id = b.hotel_id SET `b`.`status` = ?"
+	assert.Equal(t, expectedUpdate, update.GetSQL())
+	assert.Equal(t, []interface{}{1, "cancelled"}, update.GetParameters())
+
+	deleteQuery := mysql.NewQueryBuilder(db).With("closed_hotel", closed).Delete("offer").Where("hotel_id IN (SELECT id FROM closed_hotel)")
+	expectedDelete := "WITH `closed_hotel` AS (SELECT id FROM `hotel` WHERE closed = ?) " +
+		"DELETE FROM `offer` WHERE hotel_id IN (SELECT id FROM closed_hotel)"
+	assert.Equal(t, expectedDelete, deleteQuery.GetSQL())
+
+	_, err3 := mysql.NewQueryBuilder(db).With("closed_hotel", closed).Insert("audit").Value("action", "purge").PrepareAndExecute()
+	assert.EqualError(t, err3, "common table expressions are not supported by insert queries")
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+}
+
+func Test_select_modifiers_and_hints(t *testing.T) {
+	db, _, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	queryBuilder := mysql.NewQueryBuilder(db).
+		Select("h.id, c.name").
+		SelectModifiers(mysql.SqlCalcFoundRows).
+		Distinct().
+		StraightJoin().
+		MaxExecutionTime(500).
+		OptimizerHint("BKA(c)").
+		From("hotel", "h").
+		InnerJoin("city", "c", "c.id = h.city_id").
+		ForceIndex("h", "idx_city_stars").
+		IgnoreIndex("c", "PRIMARY").
+		Where("h.stars >= ?", 4)
+
+	expectedSql := "SELECT /*+ MAX_EXECUTION_TIME(500) BKA(c) */ DISTINCT STRAIGHT_JOIN SQL_CALC_FOUND_ROWS h.id, c.name " +
+		"FROM `hotel` `h` FORCE INDEX (`idx_city_stars`) INNER JOIN `city` `c` IGNORE INDEX (`PRIMARY`) ON c.id = h.city_id " +
+		"WHERE h.stars >= ?"
+	assert.Equal(t, expectedSql, queryBuilder.GetSQL())
+	assert.Equal(t, []interface{}{4}, queryBuilder.GetParameters())
+
+	_, err2 := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").UseIndex("h", "idx_city").QueryAssoc()
+	assert.EqualError(t, err2, "USE INDEX given for \"h\", which is not a table of the query")
+
+	_, err3 := mysql.NewQueryBuilder(db).Select("id").SelectModifiers("SLEEP(1)").From("hotel", "").QueryAssoc()
+	assert.EqualError(t, err3, "unknown select modifier \"SLEEP(1)\"")
+
+	_, err4 := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").OptimizerHint("*/ id, (SELECT 1) /*").QueryAssoc()
+	assert.EqualError(t, err4, "optimizer hint \"*/ id, (SELECT 1) /*\" must not close the hint comment")
+
+	deleteQuery := mysql.NewQueryBuilder(db).Delete("audit").UseIndex("audit", "idx_created").MaxExecutionTime(100)
+	_, err5 := deleteQuery.PrepareAndExecute()
+	assert.EqualError(t, err5, "index hints are not supported by single-table delete queries")
+
+	deleteTargetsQuery := mysql.NewQueryBuilder(db).Delete("audit").DeleteTargets("audit").UseIndex("audit", "idx_created")
+	assert.Equal(t, "DELETE `audit` FROM `audit` USE INDEX (`idx_created`)", deleteTargetsQuery.GetSQL())
+}
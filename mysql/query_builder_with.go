@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// With returns QueryBuilder that names the given query as a common table expression the query can range over.
+// The name may be followed by its parenthesized column list.
+func (queryBuilder *QueryBuilder) With(name string, query *QueryBuilder) *QueryBuilder {
+	queryBuilder.sqlPartsWith = append(queryBuilder.sqlPartsWith, WithSqlParts{name: name, query: query})
+
+	return queryBuilder
+}
+
+// WithRecursive returns QueryBuilder that names the given query as a recursive common table expression,
+// usually a UnionAll of the anchor query and a query joined to the common table expression itself.
+func (queryBuilder *QueryBuilder) WithRecursive(name string, query *QueryBuilder) *QueryBuilder {
+	queryBuilder.sqlPartsWith = append(queryBuilder.sqlPartsWith, WithSqlParts{name: name, query: query, recursive: true})
+
+	return queryBuilder
+}
+
+// getSQLForWith returns a with string in SQL followed by a space and its params, the common table expressions
+// are bound as subqueries.
+func (queryBuilder *QueryBuilder) getSQLForWith() (string, []interface{}) {
+	if len(queryBuilder.sqlPartsWith) == 0 {
+		return "", make([]interface{}, 0)
+	}
+
+	sqlString := "WITH "
+	expressions := make([]string, 0, len(queryBuilder.sqlPartsWith))
+	params := make([]interface{}, 0, len(queryBuilder.sqlPartsWith))
+
+	for _, v := range queryBuilder.sqlPartsWith {
+		if v.recursive {
+			sqlString = "WITH RECURSIVE "
+		}
+		expressions = append(expressions, queryBuilder.quote(v.name)+" AS "+boundPlaceholder)
+		params = append(params, v.query)
+	}
+
+	return sqlString + strings.Join(expressions, ", ") + " ", params
+}
+
+// validateWith returns an error when common table expressions are used by an insert query.
+func (queryBuilder *QueryBuilder) validateWith() error {
+	if len(queryBuilder.sqlPartsWith) > 0 && queryBuilder.queryType == Insert {
+		return fmt.Errorf("common table expressions are not supported by insert queries")
+	}
+
+	return nil
+}
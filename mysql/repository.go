@@ -0,0 +1,296 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	// Repository runs the common queries of a table whose rows are scanned into T, a struct whose fields are
+	// matched to the columns by their `db:"column"` tag, untagged fields are neither selected nor stored
+	Repository[T any] struct {
+		table      string
+		primaryKey string
+		mapping    *structMapping
+		columns    []string
+		connection *Connection
+		tx         *sql.Tx
+		err        error
+	}
+
+	// FindOptions records the filter, the ordering and the range of the rows returned by FindAll. Offset is only
+	// applied with a Limit.
+	FindOptions struct {
+		Where   Expression
+		OrderBy []Sort
+		Limit   int
+		Offset  int
+	}
+
+	// Sort records a column to sort the rows by and its order, ASC or DESC
+	Sort struct {
+		Column string
+		Order  string
+	}
+)
+
+// NewRepository returns a Repository of the table with the given primary key column, running its queries with
+// the query builders of the connection.
+func NewRepository[T any](connection *Connection, table string, primaryKey string) *Repository[T] {
+	repository := &Repository[T]{table: table, primaryKey: primaryKey, connection: connection}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		repository.err = fmt.Errorf("repository entities must be structs, got %s", t)
+		return repository
+	}
+
+	repository.mapping = getStructMapping(t)
+	if _, ok := repository.mapping.fields[primaryKey]; !ok {
+		repository.err = fmt.Errorf("repository entity %s has no field for the primary key %s", t, primaryKey)
+		return repository
+	}
+
+	repository.columns = make([]string, 0, len(repository.mapping.fields))
+	for column := range repository.mapping.fields {
+		repository.columns = append(repository.columns, column)
+	}
+	sort.Slice(repository.columns, func(i, j int) bool {
+		return lessIndex(repository.mapping.fields[repository.columns[i]], repository.mapping.fields[repository.columns[j]])
+	})
+
+	return repository
+}
+
+// WithTx returns a copy of the Repository that runs its queries in the given transaction.
+func (repository *Repository[T]) WithTx(tx *sql.Tx) *Repository[T] {
+	copied := *repository
+	copied.tx = tx
+
+	return &copied
+}
+
+// Find returns the row with the given primary key, or sql.ErrNoRows when there is none.
+func (repository *Repository[T]) Find(id interface{}) (*T, error) {
+	return repository.FindContext(context.Background(), id)
+}
+
+// FindContext returns the row with the given primary key like Find, the query is cancelled when the context is done.
+func (repository *Repository[T]) FindContext(ctx context.Context, id interface{}) (*T, error) {
+	if repository.err != nil {
+		return nil, repository.err
+	}
+
+	queryBuilder := repository.selectQuery()
+	entity := new(T)
+
+	err := queryBuilder.WhereExpr(Eq(queryBuilder.quote(repository.primaryKey), id)).QueryOneContext(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// FindBy returns the rows matching the expression.
+func (repository *Repository[T]) FindBy(expression Expression) ([]T, error) {
+	return repository.FindByContext(context.Background(), expression)
+}
+
+// FindByContext returns the rows matching the expression like FindBy, the query is cancelled when the context is
+// done.
+func (repository *Repository[T]) FindByContext(ctx context.Context, expression Expression) ([]T, error) {
+	return repository.FindAllContext(ctx, FindOptions{Where: expression})
+}
+
+// FindAll returns the rows matching the options, every row when they are empty.
+func (repository *Repository[T]) FindAll(options FindOptions) ([]T, error) {
+	return repository.FindAllContext(context.Background(), options)
+}
+
+// FindAllContext returns the rows matching the options like FindAll, the query is cancelled when the context is
+// done.
+func (repository *Repository[T]) FindAllContext(ctx context.Context, options FindOptions) ([]T, error) {
+	if repository.err != nil {
+		return nil, repository.err
+	}
+
+	queryBuilder := repository.selectQuery()
+	if !options.Where.IsEmpty() {
+		queryBuilder.WhereExpr(options.Where)
+	}
+
+	for _, v := range options.OrderBy {
+		queryBuilder.OrderBy(v.Column, v.Order)
+	}
+
+	if options.Limit > 0 {
+		queryBuilder.SetMaxResults(options.Limit).SetFirstResult(options.Offset)
+	}
+
+	entities := make([]T, 0)
+	if err := queryBuilder.QueryIntoContext(ctx, &entities); err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// Exists returns whether any row matches the expression.
+func (repository *Repository[T]) Exists(expression Expression) (bool, error) {
+	return repository.ExistsContext(context.Background(), expression)
+}
+
+// ExistsContext returns whether any row matches the expression like Exists, the query is cancelled when the
+// context is done.
+func (repository *Repository[T]) ExistsContext(ctx context.Context, expression Expression) (bool, error) {
+	if repository.err != nil {
+		return false, repository.err
+	}
+
+	queryBuilder := repository.newQueryBuilder().Select("1").From(repository.table, "").SetMaxResults(1)
+	if !expression.IsEmpty() {
+		queryBuilder.WhereExpr(expression)
+	}
+
+	rows, err := queryBuilder.QueryContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	exists := rows.Next()
+
+	return exists, rows.Err()
+}
+
+// Insert inserts the entity, leaving the primary key to the database when it is a zero value and setting it
+// to the inserted id afterwards, if the dialect supports LastInsertId.
+func (repository *Repository[T]) Insert(entity *T) error {
+	return repository.InsertContext(context.Background(), entity)
+}
+
+// InsertContext inserts the entity like Insert, the query is cancelled when the context is done.
+func (repository *Repository[T]) InsertContext(ctx context.Context, entity *T) error {
+	if repository.err != nil {
+		return repository.err
+	}
+
+	value := reflect.ValueOf(entity).Elem()
+	primaryKey := fieldByIndex(value, repository.mapping.fields[repository.primaryKey])
+	generated := primaryKey.IsZero()
+
+	queryBuilder := repository.newQueryBuilder().Insert(repository.table)
+	for _, column := range repository.columns {
+		if column == repository.primaryKey && generated {
+			continue
+		}
+		queryBuilder.Value(column, fieldByIndex(value, repository.mapping.fields[column]).Interface())
+	}
+
+	id, err := queryBuilder.PrepareAndExecuteContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if generated && id > 0 {
+		switch primaryKey.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			primaryKey.SetInt(id)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			primaryKey.SetUint(uint64(id))
+		}
+	}
+
+	return nil
+}
+
+// Update updates the row of the original entity with the columns whose values differ in the updated entity and
+// returns the number of affected rows. It runs no query when no column changed.
+func (repository *Repository[T]) Update(original T, updated T) (int64, error) {
+	return repository.UpdateContext(context.Background(), original, updated)
+}
+
+// UpdateContext updates the changed columns like Update, the query is cancelled when the context is done.
+func (repository *Repository[T]) UpdateContext(ctx context.Context, original T, updated T) (int64, error) {
+	if repository.err != nil {
+		return -1, repository.err
+	}
+
+	originalValue := reflect.ValueOf(&original).Elem()
+	updatedValue := reflect.ValueOf(&updated).Elem()
+
+	queryBuilder := repository.newQueryBuilder().Update(repository.table, "")
+	changed := false
+
+	for _, column := range repository.columns {
+		before := fieldByIndex(originalValue, repository.mapping.fields[column]).Interface()
+		after := fieldByIndex(updatedValue, repository.mapping.fields[column]).Interface()
+
+		if !reflect.DeepEqual(before, after) {
+			queryBuilder.Set(column, after)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return 0, nil
+	}
+
+	id := fieldByIndex(originalValue, repository.mapping.fields[repository.primaryKey]).Interface()
+
+	return queryBuilder.WhereExpr(Eq(queryBuilder.quote(repository.primaryKey), id)).PrepareAndExecuteContext(ctx)
+}
+
+// Delete deletes the row with the given primary key and returns the number of affected rows, the rows of soft
+// deleted tables are marked as deleted instead.
+func (repository *Repository[T]) Delete(id interface{}) (int64, error) {
+	return repository.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext deletes the row like Delete, the query is cancelled when the context is done.
+func (repository *Repository[T]) DeleteContext(ctx context.Context, id interface{}) (int64, error) {
+	if repository.err != nil {
+		return -1, repository.err
+	}
+
+	queryBuilder := repository.newQueryBuilder().Delete(repository.table)
+
+	return queryBuilder.WhereExpr(Eq(queryBuilder.quote(repository.primaryKey), id)).PrepareAndExecuteContext(ctx)
+}
+
+// newQueryBuilder returns a QueryBuilder of the connection, or of the transaction when there is one.
+func (repository *Repository[T]) newQueryBuilder() *QueryBuilder {
+	if repository.tx != nil {
+		return repository.connection.NewQueryBuilderTx(repository.tx)
+	}
+
+	return repository.connection.NewQueryBuilder()
+}
+
+// selectQuery returns QueryBuilder that selects the mapped columns of the table.
+func (repository *Repository[T]) selectQuery() *QueryBuilder {
+	queryBuilder := repository.newQueryBuilder()
+
+	columns := make([]string, 0, len(repository.columns))
+	for _, column := range repository.columns {
+		columns = append(columns, queryBuilder.quote(column))
+	}
+
+	return queryBuilder.Select(strings.Join(columns, ", ")).From(repository.table, "")
+}
+
+// lessIndex returns whether the field at index path a is declared before the one at index path b.
+func lessIndex(a []int, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
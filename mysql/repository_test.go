@@ -0,0 +1,131 @@
+package mysql_test
+
+import (
+	"database/sql"
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
+	"testing"
+)
+
+type repositoryHotel struct {
+	ID    int64   `db:"id"`
+	Name  string  `db:"name"`
+	Stars *int    `db:"stars"`
+	Notes string  `db:"-"`
+	Price float64 `db:"price"`
+	Rooms []string
+}
+
+func Test_repository_find(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	findSql := regexp.QuoteMeta("SELECT `id`, `name`, `stars`, `price` FROM `hotel` WHERE `id` = ?")
+	mock.ExpectQuery(findSql).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "stars", "price"}).AddRow(7, "Arts", 5, "120.50"))
+	mock.ExpectQuery(findSql).WithArgs(8).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "stars", "price"}))
+
+	repository := mysql.NewRepository[repositoryHotel](mysql.NewConnectionFromDB(db), "hotel", "id")
+
+	hotel, err2 := repository.Find(7)
+	assert.Nil(t, err2)
+	stars := 5
+	assert.Equal(t, &repositoryHotel{ID: 7, Name: "Arts", Stars: &stars, Price: 120.5}, hotel)
+
+	_, err3 := repository.Find(8)
+	assert.Equal(t, sql.ErrNoRows, err3)
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+}
+
+func Test_repository_find_all(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `name`, `stars`, `price` FROM `hotel` WHERE stars >= ? ORDER BY `price` DESC LIMIT 20,10")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "stars", "price"}).AddRow(1, "Arts", nil, 99).AddRow(2, "Ritz", 4, 80))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `name`, `stars`, `price` FROM `hotel` WHERE name = ?")).
+		WithArgs("Ritz").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "stars", "price"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM `hotel` WHERE name = ? LIMIT 0,1")).
+		WithArgs("Ritz").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	repository := mysql.NewRepository[repositoryHotel](mysql.NewConnectionFromDB(db), "hotel", "id")
+
+	hotels, err2 := repository.FindAll(mysql.FindOptions{
+		Where:   mysql.Gte("stars", 4),
+		OrderBy: []mysql.Sort{{Column: "price", Order: "DESC"}},
+		Limit:   10,
+		Offset:  20,
+	})
+	assert.Nil(t, err2)
+	assert.Len(t, hotels, 2)
+	assert.Nil(t, hotels[0].Stars)
+	assert.Equal(t, "Ritz", hotels[1].Name)
+
+	hotels, err3 := repository.FindBy(mysql.Eq("name", "Ritz"))
+	assert.Nil(t, err3)
+	assert.Empty(t, hotels)
+
+	exists, err4 := repository.Exists(mysql.Eq("name", "Ritz"))
+	assert.Nil(t, err4)
+	assert.True(t, exists)
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
+
+func Test_repository_insert_update_delete(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	insertSql := regexp.QuoteMeta("INSERT INTO `hotel` (`name`, `stars`, `price`) VALUES (?, ?, ?)")
+	updateSql := regexp.QuoteMeta("UPDATE `hotel` SET `name` = ? ,`price` = ? WHERE `id` = ?")
+	deleteSql := regexp.QuoteMeta("DELETE FROM `hotel` WHERE `id` = ?")
+
+	mock.ExpectPrepare(insertSql)
+	mock.ExpectExec(insertSql).WithArgs("Arts", nil, 99.5).WillReturnResult(sqlmock.NewResult(12, 1))
+	mock.ExpectPrepare(updateSql)
+	mock.ExpectExec(updateSql).WithArgs("Arts Barcelona", 120.0, 12).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(deleteSql)
+	mock.ExpectExec(deleteSql).WithArgs(12).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repository := mysql.NewRepository[repositoryHotel](mysql.NewConnectionFromDB(db), "hotel", "id")
+
+	hotel := repositoryHotel{Name: "Arts", Notes: "not stored", Price: 99.5, Rooms: []string{"101"}}
+	assert.Nil(t, repository.Insert(&hotel))
+	assert.Equal(t, int64(12), hotel.ID)
+
+	updated := hotel
+	updated.Name = "Arts Barcelona"
+	updated.Price = 120
+	updated.Notes = "still not stored"
+	updated.Rooms = []string{"101", "102"}
+
+	affected, err2 := repository.Update(hotel, updated)
+	assert.Nil(t, err2)
+	assert.Equal(t, int64(1), affected)
+
+	affected, err3 := repository.Update(updated, updated)
+	assert.Nil(t, err3)
+	assert.Equal(t, int64(0), affected)
+
+	affected, err4 := repository.Delete(12)
+	assert.Nil(t, err4)
+	assert.Equal(t, int64(1), affected)
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
+
+func Test_repository_without_primary_key_field(t *testing.T) {
+	repository := mysql.NewRepository[repositoryHotel](nil, "hotel", "hotel_id")
+
+	_, err := repository.Find(1)
+	assert.EqualError(t, err, "repository entity mysql_test.repositoryHotel has no field for the primary key hotel_id")
+}
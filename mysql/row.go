@@ -0,0 +1,93 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrStopIteration stops an Each iteration early without failing when returned by its callback.
+var ErrStopIteration = errors.New("stop iteration")
+
+// Row is the current row of an Each iteration, it is only valid until the callback returns.
+type Row struct {
+	scanner *rowScanner
+}
+
+// Each executes a select query and calls fn with one row at a time, without loading the whole result in memory.
+// The iteration stops at the first error returned by fn, ErrStopIteration stops it without failing,
+// and the rows are always closed.
+func (queryBuilder *QueryBuilder) Each(fn func(row Row) error) error {
+	return queryBuilder.EachContext(context.Background(), fn)
+}
+
+// EachContext iterates over the rows of a select query like Each, the query is cancelled when the context is done.
+func (queryBuilder *QueryBuilder) EachContext(ctx context.Context, fn func(row Row) error) error {
+	rows, err := queryBuilder.QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	if rows == nil {
+		return fmt.Errorf("each is only supported by select queries")
+	}
+	defer rows.Close()
+
+	scanner, err := newRowScanner(rows)
+	if err != nil {
+		return err
+	}
+
+	row := Row{scanner: scanner}
+	for rows.Next() {
+		if err := scanner.scan(rows); err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Columns returns the column names of the row.
+func (row Row) Columns() []string {
+	return row.scanner.columns
+}
+
+// ColumnTypes returns the column metadata of the row.
+func (row Row) ColumnTypes() []*sql.ColumnType {
+	return row.scanner.columnTypes
+}
+
+// Field returns the value of the given column, a missing column has a nil value.
+func (row Row) Field(column string) Field {
+	for i, name := range row.scanner.columns {
+		if name == column {
+			return NewField(row.scanner.values[i], row.scanner.dbTypes[i])
+		}
+	}
+
+	return NewField(nil, "")
+}
+
+// Fields returns the value of every column of the row.
+func (row Row) Fields() map[string]Field {
+	fields := make(map[string]Field, len(row.scanner.columns))
+	for i, column := range row.scanner.columns {
+		fields[column] = NewField(row.scanner.values[i], row.scanner.dbTypes[i])
+	}
+
+	return fields
+}
+
+// Scan scans the row into dest, a pointer to a tagged struct, like QueryInto does.
+func (row Row) Scan(dest interface{}) error {
+	return row.scanner.assignStruct(dest)
+}
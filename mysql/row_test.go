@@ -0,0 +1,64 @@
+package mysql_test
+
+import (
+	"errors"
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+)
+
+func Test_each_row(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	rows := sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Arts").AddRow(2, "Ibis").AddRow(3, "Ritz")
+	mock.ExpectQuery("SELECT id, name FROM `hotel`").WillReturnRows(rows)
+
+	names := make([]string, 0)
+	ids := make([]int, 0)
+	err2 := mysql.NewQueryBuilder(db).Select("id, name").From("hotel", "").Each(func(row mysql.Row) error {
+		assert.Equal(t, []string{"id", "name"}, row.Columns())
+		assert.Len(t, row.ColumnTypes(), 2)
+		names = append(names, row.Field("name").StringVal())
+
+		var h hotel
+		if err := row.Scan(&h); err != nil {
+			return err
+		}
+		ids = append(ids, int(h.ID))
+
+		if row.Fields()["id"].IntVal() == 2 {
+			return mysql.ErrStopIteration
+		}
+
+		return nil
+	})
+
+	assert.Nil(t, err2)
+	assert.Equal(t, []string{"Arts", "Ibis"}, names)
+	assert.Equal(t, []int{1, 2}, ids)
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_each_row_callback_error(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	rows := sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2)
+	mock.ExpectQuery("SELECT id FROM `hotel`").WillReturnRows(rows)
+
+	calls := 0
+	err2 := mysql.NewQueryBuilder(db).Select("id").From("hotel", "").Each(func(row mysql.Row) error {
+		calls++
+		return errors.New("export failed")
+	})
+
+	assert.EqualError(t, err2, "export failed")
+	assert.Equal(t, 1, calls)
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
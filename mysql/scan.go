@@ -0,0 +1,358 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dateTimeFractionFormat is the datetime format of the columns with fractional seconds.
+const dateTimeFractionFormat = "2006-01-02 15:04:05.999999999"
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+
+	// structMappings caches the structMapping of every struct type scanned.
+	structMappings sync.Map
+)
+
+type (
+	// structMapping records the index path of the struct field mapped to every column
+	structMapping struct {
+		fields map[string][]int
+	}
+
+	// rowScanner records the columns of a result set and scans its rows
+	rowScanner struct {
+		columns     []string
+		dbTypes     []string
+		values      []interface{}
+		valuePtrs   []interface{}
+		columnTypes []*sql.ColumnType
+	}
+)
+
+// QueryInto executes a select query and appends every row to dest, a pointer to a slice of tagged structs
+// or pointers to them, matching columns and fields by their `db:"column"` tag.
+func (queryBuilder *QueryBuilder) QueryInto(dest interface{}) error {
+	return queryBuilder.QueryIntoContext(context.Background(), dest)
+}
+
+// QueryIntoContext executes a select query like QueryInto, the query is cancelled when the context is done.
+func (queryBuilder *QueryBuilder) QueryIntoContext(ctx context.Context, dest interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("query into destination must be a pointer to a slice, got %T", dest)
+	}
+	slice = slice.Elem()
+
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("query into destination must be a slice of structs, got %T", dest)
+	}
+
+	rows, err := queryBuilder.QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	if rows == nil {
+		return fmt.Errorf("query into is only supported by select queries")
+	}
+	defer rows.Close()
+
+	scanner, err := newRowScanner(rows)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := scanner.scanStruct(rows, elem.Interface()); err != nil {
+			return err
+		}
+
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+
+	return rows.Err()
+}
+
+// QueryOne executes a select query and scans its first row into dest, a pointer to a tagged struct,
+// it returns sql.ErrNoRows when there are no rows.
+func (queryBuilder *QueryBuilder) QueryOne(dest interface{}) error {
+	return queryBuilder.QueryOneContext(context.Background(), dest)
+}
+
+// QueryOneContext executes a select query like QueryOne, the query is cancelled when the context is done.
+func (queryBuilder *QueryBuilder) QueryOneContext(ctx context.Context, dest interface{}) error {
+	rows, err := queryBuilder.QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	if rows == nil {
+		return fmt.Errorf("query one is only supported by select queries")
+	}
+	defer rows.Close()
+
+	scanner, err := newRowScanner(rows)
+	if err != nil {
+		return err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return sql.ErrNoRows
+	}
+
+	if err := scanner.scanStruct(rows, dest); err != nil {
+		return err
+	}
+
+	return rows.Err()
+}
+
+// newRowScanner returns a rowScanner for the columns of the given rows.
+func newRowScanner(rows *sql.Rows) (*rowScanner, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := &rowScanner{
+		columns:     make([]string, len(columnTypes)),
+		dbTypes:     make([]string, len(columnTypes)),
+		values:      make([]interface{}, len(columnTypes)),
+		valuePtrs:   make([]interface{}, len(columnTypes)),
+		columnTypes: columnTypes,
+	}
+
+	for i, columnType := range columnTypes {
+		scanner.columns[i] = columnType.Name()
+		scanner.dbTypes[i] = columnType.DatabaseTypeName()
+		scanner.valuePtrs[i] = &scanner.values[i]
+	}
+
+	return scanner, nil
+}
+
+// scan scans the current row into the scanner values.
+func (scanner *rowScanner) scan(rows *sql.Rows) error {
+	for i := range scanner.values {
+		scanner.values[i] = nil
+	}
+
+	return rows.Scan(scanner.valuePtrs...)
+}
+
+// scanStruct scans the current row into dest, a pointer to a tagged struct.
+func (scanner *rowScanner) scanStruct(rows *sql.Rows, dest interface{}) error {
+	if err := scanner.scan(rows); err != nil {
+		return err
+	}
+
+	return scanner.assignStruct(dest)
+}
+
+// assignStruct assigns the scanned values to the fields of dest mapped to their columns, columns without a field are
+// ignored.
+func (scanner *rowScanner) assignStruct(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be a non nil pointer to a struct, got %T", dest)
+	}
+	v = v.Elem()
+
+	mapping := getStructMapping(v.Type())
+	for i, column := range scanner.columns {
+		index, ok := mapping.fields[column]
+		if !ok {
+			continue
+		}
+
+		if err := assignValue(fieldByIndex(v, index), scanner.values[i], scanner.dbTypes[i]); err != nil {
+			return fmt.Errorf("unable to scan column %s: %w", column, err)
+		}
+	}
+
+	return nil
+}
+
+// getStructMapping returns the cached structMapping of the given struct type.
+func getStructMapping(t reflect.Type) *structMapping {
+	if mapping, ok := structMappings.Load(t); ok {
+		return mapping.(*structMapping)
+	}
+
+	mapping := &structMapping{fields: map[string][]int{}}
+	addStructFields(mapping, t, nil)
+
+	actual, _ := structMappings.LoadOrStore(t, mapping)
+
+	return actual.(*structMapping)
+}
+
+// addStructFields adds the fields of the given struct type to the mapping, flattening untagged embedded structs.
+// Only tagged fields are mapped, to their tag, untagged and `db:"-"` ones are skipped.
+func addStructFields(mapping *structMapping, t reflect.Type, parentIndex []int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("db"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		index := append(append([]int{}, parentIndex...), i)
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && tag == "" && fieldType.Kind() == reflect.Struct && !isScalarStruct(fieldType) {
+			addStructFields(mapping, fieldType, index)
+			continue
+		}
+
+		if field.PkgPath != "" || tag == "" {
+			continue
+		}
+
+		if _, exists := mapping.fields[tag]; !exists || len(index) < len(mapping.fields[tag]) {
+			mapping.fields[tag] = index
+		}
+	}
+}
+
+// isScalarStruct returns whether values of the given struct type are scanned as a whole.
+func isScalarStruct(t reflect.Type) bool {
+	return t == timeType || reflect.PtrTo(t).Implements(scannerType)
+}
+
+// fieldByIndex returns the field at the given index path, allocating the nil embedded struct pointers in the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, fieldIndex := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(fieldIndex)
+	}
+
+	return v
+}
+
+// assignValue assigns a scanned value to dst applying the same coercion Field does.
+func assignValue(dst reflect.Value, value interface{}, dbType string) error {
+	if dst.CanAddr() && dst.Addr().Type().Implements(scannerType) {
+		return dst.Addr().Interface().(sql.Scanner).Scan(value)
+	}
+
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignValue(elem.Elem(), value, dbType); err != nil {
+			return err
+		}
+		dst.Set(elem)
+
+		return nil
+	}
+
+	if dst.Type() == timeType {
+		t, err := toTime(value, dbType)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(toString(value))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(toString(value), 10, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(toString(value), 10, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(toString(value), 64)
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(toString(value))
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	default:
+		v := reflect.ValueOf(value)
+		if !v.Type().ConvertibleTo(dst.Type()) {
+			return fmt.Errorf("unable to convert %T to %s", value, dst.Type())
+		}
+		dst.Set(v.Convert(dst.Type()))
+	}
+
+	return nil
+}
+
+// toString returns the string representation of a scanned value.
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format(dateTimeFormat)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toTime returns the time of a scanned value, parsing it by its database type when it is not a time already.
+func toTime(value interface{}, dbType string) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+
+	format := dateTimeFractionFormat
+	if dbType == "DATE" {
+		format = dateFormat
+	}
+
+	return time.Parse(format, toString(value))
+}
@@ -0,0 +1,93 @@
+package mysql_test
+
+import (
+	"database/sql"
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+type audit struct {
+	Created time.Time  `db:"created"`
+	Updated *time.Time `db:"updated"`
+}
+
+type hotel struct {
+	audit
+	ID       int64          `db:"id"`
+	Name     string         `db:"name"`
+	Stars    *int           `db:"stars"`
+	Price    float64        `db:"price"`
+	Active   bool           `db:"active"`
+	Chain    sql.NullString `db:"chain"`
+	Internal string         `db:"-"`
+	Unknown  string
+}
+
+func Test_query_into_structs(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "stars", "price", "active", "chain", "created", "updated", "unknown"}).
+		AddRow([]byte("1"), []byte("Arts"), []byte("5"), []byte("120.5"), []byte("1"), []byte("Ritz"), []byte("2022-10-01 10:30:00"), nil, "x").
+		AddRow(int64(2), "Ibis", nil, 45.0, int64(0), nil, time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC), []byte("2022-10-02 08:00:00"), "y")
+	mock.ExpectQuery("SELECT (.+) FROM `hotel`").WillReturnRows(rows)
+
+	var hotels []hotel
+	err2 := mysql.NewQueryBuilder(db).Select("*").From("hotel", "").QueryInto(&hotels)
+	assert.Nil(t, err2)
+	assert.Len(t, hotels, 2)
+
+	stars := 5
+	assert.Equal(t, int64(1), hotels[0].ID)
+	assert.Equal(t, "Arts", hotels[0].Name)
+	assert.Equal(t, &stars, hotels[0].Stars)
+	assert.Equal(t, 120.5, hotels[0].Price)
+	assert.True(t, hotels[0].Active)
+	assert.Equal(t, sql.NullString{String: "Ritz", Valid: true}, hotels[0].Chain)
+	assert.Equal(t, time.Date(2022, 10, 1, 10, 30, 0, 0, time.UTC), hotels[0].Created)
+	assert.Nil(t, hotels[0].Updated)
+	assert.Empty(t, hotels[0].Unknown)
+
+	assert.Equal(t, "Ibis", hotels[1].Name)
+	assert.Nil(t, hotels[1].Stars)
+	assert.False(t, hotels[1].Active)
+	assert.False(t, hotels[1].Chain.Valid)
+	assert.Equal(t, time.Date(2022, 10, 2, 8, 0, 0, 0, time.UTC), *hotels[1].Updated)
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_query_one_struct(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	mock.ExpectQuery("SELECT id, name FROM `hotel`").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Arts"))
+	mock.ExpectQuery("SELECT id, name FROM `hotel`").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectQuery("SELECT id, name FROM `hotel`").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("three", "Ibis"))
+
+	var found hotel
+	err2 := mysql.NewQueryBuilder(db).Select("id, name").From("hotel", "").Where("id = ?", 1).QueryOne(&found)
+	assert.Nil(t, err2)
+	assert.Equal(t, "Arts", found.Name)
+
+	var missing hotel
+	err3 := mysql.NewQueryBuilder(db).Select("id, name").From("hotel", "").Where("id = ?", 2).QueryOne(&missing)
+	assert.ErrorIs(t, err3, sql.ErrNoRows)
+
+	var invalid hotel
+	err4 := mysql.NewQueryBuilder(db).Select("id, name").From("hotel", "").Where("id = ?", 3).QueryOne(&invalid)
+	assert.Error(t, err4)
+
+	var notASlice hotel
+	assert.Error(t, mysql.NewQueryBuilder(db).Select("id").From("hotel", "").QueryInto(&notASlice))
+
+	err5 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err5, "there were unfulfilled expectations: %s", err5)
+}
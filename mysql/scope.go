@@ -0,0 +1,255 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SoftDeleteScope is the name of the scopes added with AddSoftDelete.
+const SoftDeleteScope = "soft_delete"
+
+type (
+	// ScopeCondition returns the condition of a scope for a scoped table, given its quoted alias or name
+	ScopeCondition func(table string) Expression
+
+	// Scope records a condition applied to every select, update and delete query ranging over its tables,
+	// soft delete scopes also turn the delete queries into updates of their column
+	Scope struct {
+		name             string
+		tables           map[string]bool
+		condition        ScopeCondition
+		softDeleteColumn string
+	}
+)
+
+// AddScope registers a scope whose condition is applied to every select, update and delete query built by the
+// connection that ranges over one of the tables, until WithoutScope opts out of it. The conditions of the tables
+// in FROM are added to WHERE and the ones of the joined tables to their ON condition, except for RIGHT JOINs, which
+// add the conditions of the right joined table to WHERE and the ones of the tables left of it to its ON condition.
+// Scopes must be registered before the connection is used.
+//
+// Conditions may use named params, such as "tenant_id = :tenant_id", so that the queries missing SetNamedParam fail
+// instead of leaking rows.
+func (c *Connection) AddScope(name string, condition ScopeCondition, tables ...string) {
+	c.scopes = append(c.scopes, newScope(name, condition, "", tables))
+}
+
+// AddSoftDelete registers a SoftDeleteScope that filters out the rows of the tables whose column is not NULL and
+// turns the delete queries of the tables into updates setting the column to NOW().
+func (c *Connection) AddSoftDelete(column string, tables ...string) {
+	c.scopes = append(c.scopes, newScope(SoftDeleteScope, nil, column, tables))
+}
+
+// WithoutScope returns QueryBuilder that does not apply the scopes with the given name, soft deletes are disabled
+// by SoftDeleteScope.
+func (queryBuilder *QueryBuilder) WithoutScope(name string) *QueryBuilder {
+	if !queryBuilder.hasScope(name) {
+		queryBuilder.setError(fmt.Errorf("unknown scope %q", name))
+		return queryBuilder
+	}
+
+	if queryBuilder.withoutScopes == nil {
+		queryBuilder.withoutScopes = map[string]bool{}
+	}
+	queryBuilder.withoutScopes[name] = true
+
+	return queryBuilder
+}
+
+// newScope returns a Scope of the tables.
+func newScope(name string, condition ScopeCondition, softDeleteColumn string, tables []string) Scope {
+	scope := Scope{name: name, tables: map[string]bool{}, condition: condition, softDeleteColumn: softDeleteColumn}
+	for _, table := range tables {
+		scope.tables[unquoteIdentifier(table)] = true
+	}
+
+	return scope
+}
+
+// appliesTo returns whether the scope filters the table, given with or without its schema.
+func (scope Scope) appliesTo(table string) bool {
+	table = unquoteIdentifier(table)
+	if scope.tables[table] {
+		return true
+	}
+
+	dot := strings.LastIndex(table, ".")
+
+	return dot != -1 && scope.tables[table[dot+1:]]
+}
+
+// hasScope returns whether a scope with the given name is registered.
+func (queryBuilder *QueryBuilder) hasScope(name string) bool {
+	for _, scope := range queryBuilder.scopes {
+		if scope.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getScopes returns the scopes applied to the table.
+func (queryBuilder *QueryBuilder) getScopes(table string) []Scope {
+	scopes := make([]Scope, 0)
+
+	if queryBuilder.queryType == Insert {
+		return scopes
+	}
+
+	for _, scope := range queryBuilder.scopes {
+		if !queryBuilder.withoutScopes[scope.name] && scope.appliesTo(table) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// getScopeConditions returns the conditions of the scopes applied to the table with the given alias, or name.
+func (queryBuilder *QueryBuilder) getScopeConditions(table string, alias string) []Expression {
+	scopes := queryBuilder.getScopes(table)
+	conditions := make([]Expression, 0, len(scopes))
+
+	if alias == "" {
+		alias = table
+	}
+	quoted := queryBuilder.quote(alias)
+
+	for _, scope := range scopes {
+		if scope.softDeleteColumn != "" {
+			conditions = append(conditions, IsNull(quoted+"."+queryBuilder.quote(scope.softDeleteColumn)))
+			continue
+		}
+		conditions = append(conditions, scope.condition(quoted))
+	}
+
+	return conditions
+}
+
+// getJoinCondition returns the ON condition of the join at the given position together with the scope conditions
+// placed in it.
+func (queryBuilder *QueryBuilder) getJoinCondition(position int, join JoinSqlParts) Expression {
+	_, on, _ := queryBuilder.getScopePlacement()
+
+	return And(append([]Expression{Expr(join.joinCondition)}, on[position]...)...)
+}
+
+// getWhere returns the restriction of the query together with the scope conditions placed in WHERE.
+func (queryBuilder *QueryBuilder) getWhere() Expression {
+	where, _, _ := queryBuilder.getScopePlacement()
+
+	return And(append([]Expression{queryBuilder.sqlPartsWhere}, where...)...)
+}
+
+// getScopePlacement returns the scope conditions placed in WHERE and the ones placed in the ON condition of every
+// join by position, so that the rows of the outer joined tables are filtered without dropping the null-extended
+// ones. The scopes of the tables left of a RIGHT JOIN are null-extended by it, so they move to its ON condition,
+// while the scopes of the right joined table move on to WHERE, or to the ON condition of the next RIGHT JOIN.
+// It returns an error when a scope condition has to go into the ON condition of a join with USING.
+func (queryBuilder *QueryBuilder) getScopePlacement() ([]Expression, map[int][]Expression, error) {
+	pending := make([]Expression, 0)
+	on := map[int][]Expression{}
+	var err error
+
+	for _, v := range queryBuilder.sqlPartsFrom {
+		if v.subQuery == nil {
+			pending = append(pending, queryBuilder.getScopeConditions(v.table, v.alias)...)
+		}
+	}
+
+	if queryBuilder.flag != IsJoin {
+		return pending, on, nil
+	}
+
+	for i, v := range queryBuilder.sqlPartsJoin {
+		own := make([]Expression, 0)
+		if v.joinSubQuery == nil {
+			own = queryBuilder.getScopeConditions(v.joinTable, v.joinAlias)
+		}
+
+		switch {
+		case v.joinType == Right:
+			if len(v.joinUsing) > 0 && len(pending) > 0 && err == nil {
+				err = fmt.Errorf("scoped tables can not be right joined with USING to %s, join it with an ON condition", v.joinTable)
+			}
+			on[i], pending = pending, own
+		case len(v.joinUsing) == 0:
+			on[i] = own
+		case v.joinType == Inner:
+			pending = append(pending, own...)
+		case len(own) > 0 && err == nil:
+			err = fmt.Errorf("scoped table %s can not be %s joined with USING, join it with an ON condition",
+				v.joinTable, strings.ToLower(v.joinType))
+		}
+	}
+
+	return pending, on, err
+}
+
+// getSoftDeleteColumn returns the soft delete column of the table of a single table delete query, if any.
+func (queryBuilder *QueryBuilder) getSoftDeleteColumn() string {
+	if queryBuilder.queryType != Delete || len(queryBuilder.sqlPartsFrom) == 0 || queryBuilder.isMultiTable() {
+		return ""
+	}
+
+	if from := queryBuilder.sqlPartsFrom[0]; from.subQuery == nil {
+		for _, scope := range queryBuilder.getScopes(from.table) {
+			if scope.softDeleteColumn != "" {
+				return scope.softDeleteColumn
+			}
+		}
+	}
+
+	return ""
+}
+
+// getSQLForSoftDelete returns the update string in SQL that soft deletes the rows of a delete query and its params.
+func (queryBuilder *QueryBuilder) getSQLForSoftDelete(column string) (string, []interface{}) {
+	fromSql, params := queryBuilder.getFromClauses()
+
+	sqlString := "UPDATE " + queryBuilder.getSQLForOptimizerHints() + fromSql + " SET " + queryBuilder.quote(column) + " = NOW()"
+
+	if where := queryBuilder.getWhere(); !where.IsEmpty() {
+		sqlString += " WHERE " + where.sql
+		params = append(params, where.params...)
+	}
+
+	return sqlString + queryBuilder.getSQLForOrderBy() + queryBuilder.getSQLForRowLimit(), params
+}
+
+// validateScopes returns an error when a multi-table delete query deletes from a soft deleted table, or a scope
+// condition has to go into the ON condition of a join with USING.
+func (queryBuilder *QueryBuilder) validateScopes() error {
+	if queryBuilder.queryType == Delete && queryBuilder.isMultiTable() {
+		for _, target := range queryBuilder.getDeleteTargets() {
+			table := queryBuilder.getTableOf(target)
+			for _, scope := range queryBuilder.getScopes(table) {
+				if scope.softDeleteColumn != "" {
+					return fmt.Errorf("soft deleted table %s is not supported by multi-table delete queries", table)
+				}
+			}
+		}
+	}
+
+	_, _, err := queryBuilder.getScopePlacement()
+
+	return err
+}
+
+// getTableOf returns the table with the given alias, or name, among the tables of the query.
+func (queryBuilder *QueryBuilder) getTableOf(alias string) string {
+	for _, v := range queryBuilder.sqlPartsFrom {
+		if v.alias == alias || (v.alias == "" && v.table == alias) {
+			return v.table
+		}
+	}
+
+	for _, v := range queryBuilder.sqlPartsJoin {
+		if v.joinAlias == alias || (v.joinAlias == "" && v.joinTable == alias) {
+			return v.joinTable
+		}
+	}
+
+	return alias
+}
@@ -0,0 +1,128 @@
+package mysql_test
+
+import (
+	"github.com/atrapalo/go-base/mysql"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
+	"testing"
+)
+
+func newScopedConnection(t *testing.T) (*mysql.Connection, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.Nilf(t, err, "an error '%s' was not expected when opening a mysql database connection", err)
+
+	connection := mysql.NewConnectionFromDB(db)
+	connection.AddSoftDelete("deleted_at", "hotel", "room")
+	connection.AddScope("tenant", func(table string) mysql.Expression {
+		return mysql.Expr(table + ".`tenant_id` = :tenant_id")
+	}, "hotel")
+
+	return connection, mock
+}
+
+func Test_scopes_filter_select_queries(t *testing.T) {
+	connection, mock := newScopedConnection(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT h.id, r.id FROM `hotel` `h` LEFT JOIN `room` `r` ON (r.hotel_id = h.id) AND (`r`.`deleted_at` IS NULL) "+
+		"WHERE (h.stars > ?) AND (`h`.`deleted_at` IS NULL) AND (`h`.`tenant_id` = ?)")).
+		WithArgs(3, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT h.id, r.id FROM `hotel` `h` RIGHT JOIN `room` `r` ON (r.hotel_id = h.id) AND (`h`.`deleted_at` IS NULL) " +
+		"AND (`h`.`tenant_id` = ?) WHERE `r`.`deleted_at` IS NULL")).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT h.id FROM `hotel` `h` WHERE `h`.`tenant_id` = ?")).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err1 := connection.NewQueryBuilder().
+		Select("h.id, r.id").
+		From("hotel", "h").
+		LeftJoin("room", "r", "r.hotel_id = h.id").
+		Where("h.stars > ?", 3).
+		SetNamedParam("tenant_id", 9).
+		QueryAssoc()
+	assert.Nil(t, err1)
+
+	_, err2 := connection.NewQueryBuilder().
+		Select("h.id, r.id").
+		From("hotel", "h").
+		RightJoin("room", "r", "r.hotel_id = h.id").
+		SetNamedParam("tenant_id", 9).
+		QueryAssoc()
+	assert.Nil(t, err2)
+
+	_, err3 := connection.NewQueryBuilder().Select("h.id").From("hotel", "h").WithoutScope(mysql.SoftDeleteScope).SetNamedParam("tenant_id", 9).QueryAssoc()
+	assert.Nil(t, err3)
+
+	_, err4 := connection.NewQueryBuilder().Select("h.id").From("hotel", "h").QueryAssoc()
+	assert.EqualError(t, err4, "missing value for named param :tenant_id")
+
+	_, err5 := connection.NewQueryBuilder().Select("id").From("hotel", "").WithoutScope("tenants").SetNamedParam("tenant_id", 9).QueryAssoc()
+	assert.EqualError(t, err5, `unknown scope "tenants"`)
+
+	err6 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err6, "there were unfulfilled expectations: %s", err6)
+}
+
+func Test_scopes_with_positional_params_and_set_param(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	connection := mysql.NewConnectionFromDB(db)
+	connection.AddScope("tenant", func(table string) mysql.Expression {
+		return mysql.Expr(table+".`tenant_id` = ?", 9)
+	}, "hotel", "room")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT h.id FROM `hotel` `h` LEFT JOIN `room` `r` ON (r.hotel_id = h.id AND r.beds > ?) "+
+		"AND (`r`.`tenant_id` = ?) WHERE (h.id = ?) AND (`h`.`tenant_id` = ?)")).
+		WithArgs(2, 9, 1, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err2 := connection.NewQueryBuilder().
+		Select("h.id").
+		From("hotel", "h").
+		LeftJoin("room", "r", "r.hotel_id = h.id AND r.beds > ?").
+		Where("h.id = ?").
+		SetParam(2).
+		SetParam(1).
+		QueryAssoc()
+	assert.Nil(t, err2)
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_scopes_soft_delete(t *testing.T) {
+	connection, mock := newScopedConnection(t)
+
+	softDeleteSql := regexp.QuoteMeta("UPDATE `room` SET `deleted_at` = NOW() WHERE (id = ?) AND (`room`.`deleted_at` IS NULL)")
+	deleteSql := regexp.QuoteMeta("DELETE FROM `room` WHERE id = ?")
+	updateSql := regexp.QuoteMeta("UPDATE `room` SET `beds` = ? WHERE (id = ?) AND (`room`.`deleted_at` IS NULL)")
+
+	mock.ExpectPrepare(softDeleteSql)
+	mock.ExpectExec(softDeleteSql).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(deleteSql)
+	mock.ExpectExec(deleteSql).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(updateSql)
+	mock.ExpectExec(updateSql).WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err1 := connection.NewQueryBuilder().Delete("room").Where("id = ?", 3).PrepareAndExecute()
+	assert.Nil(t, err1)
+
+	_, err2 := connection.NewQueryBuilder().Delete("room").Where("id = ?", 3).WithoutScope(mysql.SoftDeleteScope).PrepareAndExecute()
+	assert.Nil(t, err2)
+
+	_, err3 := connection.NewQueryBuilder().Update("room", "").Set("beds", 2).Where("id = ?", 3).PrepareAndExecute()
+	assert.Nil(t, err3)
+
+	_, err4 := connection.NewQueryBuilder().DeleteFrom("room", "r").InnerJoin("hotel", "h", "h.id = r.hotel_id").WithoutScope("tenant").PrepareAndExecute()
+	assert.EqualError(t, err4, "soft deleted table room is not supported by multi-table delete queries")
+
+	_, err5 := connection.NewQueryBuilder().Select("*").From("hotel", "h").LeftJoinUsing("room", "r", "hotel_id").SetNamedParam("tenant_id", 9).QueryAssoc()
+	assert.EqualError(t, err5, "scoped table room can not be left joined with USING, join it with an ON condition")
+
+	err6 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err6, "there were unfulfilled expectations: %s", err6)
+}
@@ -0,0 +1,183 @@
+package mysql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// maxCachedStatementLength is the max length of the SQL text of the statements kept, larger ones such as batched
+// inserts of many rows are rarely repeated and would evict the statements that are.
+const maxCachedStatementLength = 4096
+
+// ErrStatementCacheClosed is returned when a statement is prepared through a closed statement cache.
+var ErrStatementCacheClosed = errors.New("statement cache is closed")
+
+type (
+	// StatementCacheStats records the hits, misses and evictions of a statement cache and its current size.
+	StatementCacheStats struct {
+		Hits, Misses, Evictions uint64
+		Size                    int
+	}
+
+	// cachedStatement records a prepared statement, the number of executions using it and whether it was evicted,
+	// evicted statements are closed once no execution is using them.
+	cachedStatement struct {
+		query   string
+		stmt    *sql.Stmt
+		refs    int
+		evicted bool
+	}
+
+	// statementCache keeps the most recently used prepared statements of a database keyed by their SQL text.
+	statementCache struct {
+		mu                      sync.Mutex
+		db                      *sql.DB
+		capacity                int
+		closed                  bool
+		items                   map[string]*list.Element
+		lru                     *list.List
+		hits, misses, evictions uint64
+	}
+)
+
+// newStatementCache returns a statement cache of the given database that keeps up to capacity statements.
+func newStatementCache(db *sql.DB, capacity int) *statementCache {
+	return &statementCache{
+		db:       db,
+		capacity: capacity,
+		items:    map[string]*list.Element{},
+		lru:      list.New(),
+	}
+}
+
+// prepare returns the cached statement of the query, preparing and caching it when there is none, and the function
+// to call once the statement is not used anymore. Queries longer than maxCachedStatementLength are never cached.
+func (cache *statementCache) prepare(ctx context.Context, query string) (*sql.Stmt, func(), error) {
+	cache.mu.Lock()
+	if cache.closed {
+		cache.mu.Unlock()
+		return nil, nil, ErrStatementCacheClosed
+	}
+	if element, ok := cache.items[query]; ok {
+		entry := cache.acquire(element)
+		cache.hits++
+		cache.mu.Unlock()
+
+		return entry.stmt, func() { cache.release(entry) }, nil
+	}
+	cache.misses++
+	cache.mu.Unlock()
+
+	stmt, err := cache.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.closed {
+		stmt.Close()
+		return nil, nil, ErrStatementCacheClosed
+	}
+
+	if cache.capacity < 1 || len(query) > maxCachedStatementLength {
+		return stmt, func() { stmt.Close() }, nil
+	}
+
+	if element, ok := cache.items[query]; ok {
+		stmt.Close()
+		entry := cache.acquire(element)
+
+		return entry.stmt, func() { cache.release(entry) }, nil
+	}
+
+	entry := &cachedStatement{query: query, stmt: stmt, refs: 1}
+	cache.items[query] = cache.lru.PushFront(entry)
+
+	for cache.lru.Len() > cache.capacity {
+		cache.evict(cache.lru.Back())
+		cache.evictions++
+	}
+
+	return stmt, func() { cache.release(entry) }, nil
+}
+
+// acquire marks the cached statement as used by one more execution and as the most recently used one.
+func (cache *statementCache) acquire(element *list.Element) *cachedStatement {
+	cache.lru.MoveToFront(element)
+
+	entry := element.Value.(*cachedStatement)
+	entry.refs++
+
+	return entry
+}
+
+// release marks the cached statement as used by one less execution, closing it when it was evicted.
+func (cache *statementCache) release(entry *cachedStatement) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// evict removes the statement from the cache and closes it unless an execution is still using it.
+func (cache *statementCache) evict(element *list.Element) error {
+	entry := cache.lru.Remove(element).(*cachedStatement)
+	delete(cache.items, entry.query)
+
+	entry.evicted = true
+	if entry.refs > 0 {
+		return nil
+	}
+
+	return entry.stmt.Close()
+}
+
+// resize sets the max number of statements kept, evicting the least recently used ones that do not fit.
+func (cache *statementCache) resize(capacity int) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.capacity = capacity
+	for cache.lru.Len() > 0 && cache.lru.Len() > capacity {
+		cache.evict(cache.lru.Back())
+		cache.evictions++
+	}
+}
+
+// stats returns the cache counters and its current size.
+func (cache *statementCache) stats() StatementCacheStats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return StatementCacheStats{
+		Hits:      cache.hits,
+		Misses:    cache.misses,
+		Evictions: cache.evictions,
+		Size:      cache.lru.Len(),
+	}
+}
+
+// close evicts every statement and stops caching new ones, it returns the first error closing them.
+func (cache *statementCache) close() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.closed = true
+
+	var firstErr error
+	for cache.lru.Len() > 0 {
+		if err := cache.evict(cache.lru.Back()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
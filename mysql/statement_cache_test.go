@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_statement_cache_evicts_least_recently_used(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	ctx := context.Background()
+	cache := newStatementCache(db, 2)
+
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT 1")).WillBeClosed()
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT 2"))
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT 3"))
+
+	for _, query := range []string{"SELECT 1", "SELECT 2", "SELECT 1", "SELECT 2", "SELECT 3"} {
+		_, release, err := cache.prepare(ctx, query)
+		assert.Nil(t, err)
+		release()
+	}
+
+	err2 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err2, "there were unfulfilled expectations: %s", err2)
+	assert.Equal(t, StatementCacheStats{Hits: 2, Misses: 3, Evictions: 1, Size: 2}, cache.stats())
+
+	_, ok := cache.items["SELECT 1"]
+	assert.False(t, ok)
+}
+
+func Test_statement_cache_closes_evicted_statement_once_released(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	ctx := context.Background()
+	cache := newStatementCache(db, 1)
+
+	mock.ExpectPrepare(regexp.QuoteMeta("DELETE FROM `hotel` WHERE id = ?")).WillBeClosed()
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT 1"))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `hotel` WHERE id = ?")).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	stmt, release, err2 := cache.prepare(ctx, "DELETE FROM `hotel` WHERE id = ?")
+	assert.Nil(t, err2)
+
+	_, releaseOther, err3 := cache.prepare(ctx, "SELECT 1")
+	assert.Nil(t, err3)
+	releaseOther()
+	assert.Equal(t, StatementCacheStats{Misses: 2, Evictions: 1, Size: 1}, cache.stats())
+
+	_, err4 := stmt.ExecContext(ctx, 3)
+	assert.Nil(t, err4)
+
+	release()
+	_, err5 := stmt.ExecContext(ctx, 3)
+	assert.EqualError(t, err5, "sql: statement is closed")
+
+	err6 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err6, "there were unfulfilled expectations: %s", err6)
+}
+
+func Test_statement_cache_does_not_cache_long_statements(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	query := "INSERT INTO `hotel` (`name`) VALUES (?)" + strings.Repeat(", (?)", maxCachedStatementLength/5)
+	cache := newStatementCache(db, 2)
+
+	mock.ExpectPrepare(regexp.QuoteMeta(query)).WillBeClosed()
+
+	_, release, err2 := cache.prepare(context.Background(), query)
+	assert.Nil(t, err2)
+	release()
+
+	assert.Equal(t, StatementCacheStats{Misses: 1}, cache.stats())
+
+	err3 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err3, "there were unfulfilled expectations: %s", err3)
+}
+
+func Test_statement_cache_is_safe_for_concurrent_use(t *testing.T) {
+	db, mock, err1 := sqlmock.New()
+	assert.Nilf(t, err1, "an error '%s' was not expected when opening a mysql database connection", err1)
+
+	ctx := context.Background()
+	cache := newStatementCache(db, 2)
+
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT 1")).WillBeClosed()
+
+	_, release, err2 := cache.prepare(ctx, "SELECT 1")
+	assert.Nil(t, err2)
+	release()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, release, err := cache.prepare(ctx, "SELECT 1")
+			assert.Nil(t, err)
+			cache.stats()
+			release()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, StatementCacheStats{Hits: 50, Misses: 1, Size: 1}, cache.stats())
+
+	assert.Nil(t, cache.close())
+	_, _, err3 := cache.prepare(ctx, "SELECT 1")
+	assert.True(t, errors.Is(err3, ErrStatementCacheClosed))
+
+	err4 := mock.ExpectationsWereMet()
+	assert.Nilf(t, err4, "there were unfulfilled expectations: %s", err4)
+}